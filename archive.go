@@ -0,0 +1,225 @@
+package dataparse
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FromZip returns maps read from the members of a zip archive.
+//
+// Members are read if their extension matches a registered format,
+// including compressed members like data.csv.gz. Members can be
+// selected with WithInclude and WithExclude.
+// The member path is recorded in the provenance of each map.
+//
+// The zip format requires random access. If the reader does not
+// implement io.ReaderAt the input is read into memory first.
+//
+// FromZip is a wrapper around ZipAll, returning the results in
+// a channel.
+func FromZip(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromZip(cfg, opts...))
+}
+
+// ZipAll returns an iterator over the maps read from the members of
+// a zip archive, see FromZip.
+func ZipAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromZip(cfg, opts...)
+}
+
+func fromZip(cfg *fromConfig, opts ...FromOption) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		readerAt, size, ok := readerAtSize(cfg.reader)
+		if !ok {
+			b, err := io.ReadAll(cfg.reader)
+			if err != nil {
+				yield(nil, fmt.Errorf("dataparse: error reading zip archive: %w", err))
+				return
+			}
+			readerAt = bytes.NewReader(b)
+			size = int64(len(b))
+		}
+
+		zr, err := zip.NewReader(readerAt, size)
+		if err != nil {
+			yield(nil, fmt.Errorf("dataparse: error opening zip archive: %w", err))
+			return
+		}
+
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || !cfg.readMember(f.Name) {
+				continue
+			}
+
+			member, err := f.Open()
+			if err != nil {
+				if !cfg.memberError(yield, f.Name, err) {
+					return
+				}
+				continue
+			}
+
+			if !cfg.readArchiveMember(yield, f.Name, member, opts...) {
+				return
+			}
+		}
+	})
+}
+
+// FromTar returns maps read from the members of a tar archive.
+//
+// Compressed archives like .tar.gz or .tgz are handled by From.
+// Members are selected like in FromZip.
+//
+// FromTar is a wrapper around TarAll, returning the results in
+// a channel.
+func FromTar(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromTar(cfg, opts...))
+}
+
+// TarAll returns an iterator over the maps read from the members of
+// a tar archive, see FromTar.
+func TarAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromTar(cfg, opts...)
+}
+
+func fromTar(cfg *fromConfig, opts ...FromOption) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		tr := tar.NewReader(cfg.reader)
+
+		for {
+			header, err := tr.Next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, fmt.Errorf("dataparse: error reading tar archive: %w", err))
+				}
+				return
+			}
+
+			if header.Typeflag != tar.TypeReg || !cfg.readMember(header.Name) {
+				continue
+			}
+
+			if !cfg.readArchiveMember(yield, header.Name, io.NopCloser(tr), opts...) {
+				return
+			}
+		}
+	})
+}
+
+// readMember returns true if the archive member with the given name
+// should be read based on the include and exclude patterns. Without
+// include patterns only members with a known extension are read.
+func (cfg *fromConfig) readMember(name string) bool {
+	if matchAny(cfg.exclude, name) {
+		return false
+	}
+
+	if len(cfg.include) > 0 {
+		return matchAny(cfg.include, name)
+	}
+
+	return hasKnownExtension(name)
+}
+
+// hasKnownExtension returns true if the extension of name matches
+// a registered format after stripping the extensions of registered
+// decompressors.
+func hasKnownExtension(name string) bool {
+	ext := normalizeExt(filepath.Ext(name))
+	for {
+		if _, ok := decompressors[ext]; !ok {
+			break
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		ext = normalizeExt(filepath.Ext(name))
+	}
+	_, ok := formatsByExt[ext]
+	return ok
+}
+
+// matchAny returns true if the name or its base name match any of the
+// patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readArchiveMember yields the maps read from an archive member.
+// The member is read with the format matching its extension.
+// It returns false if the iteration should stop.
+func (cfg *fromConfig) readArchiveMember(yield func(*Map, error) bool, name string, reader io.ReadCloser, opts ...FromOption) bool {
+	memberOpts := slices.Concat(opts, []FromOption{WithFormat(""), withMember(name)})
+	if cfg.source != "" {
+		memberOpts = append(memberOpts, WithSource(cfg.source))
+	}
+
+	memberCfg := newFromConfig(memberOpts...)
+	memberCfg.reader = reader
+	memberCfg.closers = append(memberCfg.closers, reader.Close)
+
+	seq, err := memberCfg.open(name, memberOpts...)
+	if err != nil {
+		return cfg.memberError(yield, name, err)
+	}
+
+	for m, err := range seq {
+		if err != nil {
+			if !cfg.forwardError(yield, err) {
+				return false
+			}
+			continue
+		}
+		if m.Provenance.Member == "" {
+			// formats not recording provenance
+			m.Provenance.Source = cfg.source
+			m.Provenance.Member = name
+		}
+		if !yield(m, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// forwardError yields an error of an archive member and returns true
+// if reading should continue according to the error policy.
+// The errors of all members count towards WithMaxErrors.
+func (cfg *fromConfig) forwardError(yield func(*Map, error) bool, err error) bool {
+	if errors.Is(err, ErrTooManyErrors) {
+		yield(nil, err)
+		return false
+	}
+	return cfg.recordError(yield, err)
+}
+
+// memberError yields an error for an archive member that could not be
+// read and returns true if reading should continue according to the
+// error policy.
+func (cfg *fromConfig) memberError(yield func(*Map, error) bool, name string, err error) bool {
+	return cfg.recordError(yield, fmt.Errorf("dataparse: error reading archive member %q: %w", name, err))
+}
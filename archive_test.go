@@ -0,0 +1,187 @@
+package dataparse
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveMembers = []struct {
+	name string
+	data string
+}{
+	{"users.csv", "id,name\n1,a\n2,b\n"},
+	{"nested/more.json", `[{"id": 3}, {"id": 4}]`},
+	{"readme.txt", "not data"},
+	{"export.dat", `{"id": 7}` + "\n"},
+	{"nested/compressed.ndjson.gz", ""},
+}
+
+func archiveMemberData(t *testing.T, name, data string) []byte {
+	t.Helper()
+	if filepath.Ext(name) != ".gz" {
+		return []byte(data)
+	}
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	_, err := w.Write([]byte(`{"id": 5}` + "\n" + `{"id": 6}` + "\n"))
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	return buf.Bytes()
+}
+
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	_, err := w.Create("nested/")
+	require.Nil(t, err)
+	for _, member := range archiveMembers {
+		f, err := w.Create(member.name)
+		require.Nil(t, err)
+		_, err = f.Write(archiveMemberData(t, member.name, member.data))
+		require.Nil(t, err)
+	}
+	require.Nil(t, w.Close())
+	require.Nil(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func writeTarGz(t *testing.T, path string) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	w := tar.NewWriter(gw)
+	require.Nil(t, w.WriteHeader(&tar.Header{Name: "nested/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for _, member := range archiveMembers {
+		data := archiveMemberData(t, member.name, member.data)
+		require.Nil(t, w.WriteHeader(&tar.Header{
+			Name:     member.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0o644,
+			Size:     int64(len(data)),
+		}))
+		_, err := w.Write(data)
+		require.Nil(t, err)
+	}
+	require.Nil(t, w.Close())
+	require.Nil(t, gw.Close())
+	require.Nil(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func TestFrom_Archives(t *testing.T) {
+	dir := t.TempDir()
+	writeZip(t, filepath.Join(dir, "bundle.zip"))
+	writeTarGz(t, filepath.Join(dir, "bundle.tar.gz"))
+	writeTarGz(t, filepath.Join(dir, "bundle.tgz"))
+
+	for _, name := range []string{"bundle.zip", "bundle.tar.gz", "bundle.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			members := []string{}
+			ids := []int{}
+			for m, err := range All(path) {
+				require.Nil(t, err)
+				ids = append(ids, m.MustGet("id").MustInt())
+				members = append(members, m.Provenance.Member)
+				assert.Equal(t, path, m.Provenance.Source)
+			}
+			assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, ids)
+			assert.Equal(t, []string{
+				"users.csv", "users.csv",
+				"nested/more.json", "nested/more.json",
+				"nested/compressed.ndjson.gz", "nested/compressed.ndjson.gz",
+			}, members)
+		})
+	}
+}
+
+func TestFrom_ArchiveIncludeExclude(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	writeZip(t, path)
+
+	ids, errs := collect(t, All(path, WithInclude("*.json", "*.csv")))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{1, 2, 3, 4}, ids)
+
+	ids, errs = collect(t, All(path, WithExclude("nested/*")))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{1, 2}, ids)
+
+	ids, errs = collect(t, All(path, WithInclude("nested/*"), WithExclude("*.gz")))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{3, 4}, ids)
+
+	// included members with unknown extensions are detected
+	ids, errs = collect(t, All(path, WithInclude("*.dat", "users.csv")))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{1, 2, 7}, ids)
+}
+
+func TestZipAll_Reader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.zip")
+	writeZip(t, path)
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	// io.MultiReader does not implement io.ReaderAt
+	ids, errs := collect(t, ZipAll(io.MultiReader(bytes.NewReader(data))))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, ids)
+}
+
+func TestTarAll_MemberError(t *testing.T) {
+	data := tarMembers(t, map[string]string{
+		"broken.json": `{"id": `,
+		"valid.csv":   "id\n1\n",
+	})
+
+	ids, errs := collect(t, TarAll(bytes.NewReader(data), WithErrorPolicy(ErrorPolicySkip)))
+	assert.Equal(t, []int{1}, ids)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "broken.json")
+}
+
+func TestTarAll_MemberErrorStop(t *testing.T) {
+	data := tarMembers(t, map[string]string{
+		"broken.csv": "id\n1\n2,3\n",
+		"valid.csv":  "id\n4\n",
+	})
+
+	ids, errs := collect(t, TarAll(bytes.NewReader(data)))
+	assert.Equal(t, []int{1}, ids)
+	require.Len(t, errs, 1)
+
+	var errRecord ErrRecord
+	require.ErrorAs(t, errs[0], &errRecord)
+	assert.Equal(t, "broken.csv", errRecord.Member)
+	assert.Equal(t, 3, errRecord.Line)
+}
+
+// tarMembers returns a tar archive with the members in name order.
+func tarMembers(t *testing.T, members map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := tar.NewWriter(buf)
+	for _, name := range slices.Sorted(maps.Keys(members)) {
+		require.Nil(t, w.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Mode:     0o644,
+			Size:     int64(len(members[name])),
+		}))
+		_, err := w.Write([]byte(members[name]))
+		require.Nil(t, err)
+	}
+	require.Nil(t, w.Close())
+	return buf.Bytes()
+}
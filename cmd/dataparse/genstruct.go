@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ntnn/dataparse"
+)
+
+func runGenStruct(cmd *command, args []string) error {
+	name := cmd.flags.String("name", "Record", "name of the generated struct")
+	pkg := cmd.flags.String("package", "", "package of the generated file, without a package only the types are printed")
+	args, err := cmd.parse(args, "[input]", 0, 1)
+	if err != nil {
+		return err
+	}
+
+	if !token.IsIdentifier(*name) || !token.IsExported(*name) {
+		return fmt.Errorf("gen-struct: name must be an exported identifier, got %q", *name)
+	}
+
+	schema, err := dataparse.InferSchema(cmd.input(arg(args, 0)), dataparse.WithSchemaExamples(0))
+	if err != nil {
+		return err
+	}
+
+	src, err := genStruct(*pkg, *name, schema)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.stdout.Write(src)
+	return err
+}
+
+// genStruct returns the formatted source of a struct named name for
+// the maps described by schema, followed by the structs of nested
+// maps.
+func genStruct(pkg, name string, schema *dataparse.Schema) ([]byte, error) {
+	g := &structGen{
+		names:   map[string]bool{},
+		imports: map[string]bool{},
+	}
+	g.structType(name, schema)
+	if g.optional != "" {
+		g.decls = append(g.decls, fmt.Sprintf(optionalSource, g.optional))
+	}
+
+	var b strings.Builder
+	if pkg != "" {
+		fmt.Fprintf(&b, "package %s\n\n", pkg)
+		writeImports(&b, slices.Sorted(maps.Keys(g.imports)))
+	}
+	b.WriteString(strings.Join(g.decls, "\n"))
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("gen-struct: error formatting generated source: %w\n%s", err, b.String())
+	}
+	return src, nil
+}
+
+// writeImports writes the import declaration for imports, grouping
+// the standard library packages before the others.
+func writeImports(b *strings.Builder, imports []string) {
+	switch len(imports) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(b, "import %q\n\n", imports[0])
+		return
+	}
+
+	std, other := []string{}, []string{}
+	for _, imp := range imports {
+		if first, _, _ := strings.Cut(imp, "/"); strings.Contains(first, ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+
+	b.WriteString("import (\n")
+	for i, group := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 && len(group) > 0 {
+			b.WriteString("\n")
+		}
+		for _, imp := range group {
+			fmt.Fprintf(b, "\t%q\n", imp)
+		}
+	}
+	b.WriteString(")\n\n")
+}
+
+type structGen struct {
+	decls    []string
+	names    map[string]bool
+	imports  map[string]bool
+	optional string
+}
+
+// structType adds the declaration of a struct for schema and returns
+// its name, which is name with a number appended if the name is taken.
+func (g *structGen) structType(name string, schema *dataparse.Schema) string {
+	name = unique(g.names, name)
+
+	// reserve the position so the struct is declared before the
+	// structs of its fields
+	i := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for _, field := range schema.Fields {
+		fieldName := unique(fields, goName(field.Key))
+		fmt.Fprintf(&b, "\t%s %s %s\n", fieldName, g.fieldType(name+fieldName, field), structTag(field.Key))
+	}
+	b.WriteString("}\n")
+
+	g.decls[i] = b.String()
+	return name
+}
+
+// fieldType returns the Go type of the values of field, declaring
+// structs named name for maps.
+func (g *structGen) fieldType(name string, field *dataparse.FieldSchema) string {
+	types := []string{}
+	for jsonType := range field.JSONTypes {
+		if jsonType != "null" {
+			types = append(types, jsonType)
+		}
+	}
+
+	var t string
+	switch {
+	case len(types) == 0:
+		return "any"
+	case len(types) == 1 && types[0] == "object" && field.Fields != nil:
+		t = g.structType(name, field.Fields)
+	case len(types) == 1 && types[0] == "array" && field.Items != nil:
+		t = "[]" + g.fieldType(singular(name), field.Items)
+	case slices.Contains(types, "object") || slices.Contains(types, "array"):
+		return "any"
+	default:
+		t = g.scalarType(field)
+	}
+
+	nullable := field.Nullable()
+	if t == "string" || t == "[]byte" {
+		// empty strings are valid strings
+		nullable = field.Missing > 0 || field.Nulls > 0
+	}
+	if nullable {
+		return g.optionalType() + "[" + t + "]"
+	}
+	return t
+}
+
+// optionalSource is the declaration of the type of fields that can be
+// missing, nil or empty. Value.To leaves pointers set for these values
+// or fails to convert them, e.g. empty CSV cells to numbers.
+const optionalSource = `// %[1]s holds a value that is not valid if it is missing, nil or
+// an empty string. Missing keys are only skipped with
+// dataparse.WithIgnoreNoValidKeyError.
+type %[1]s[T any] struct {
+	Value T
+	Valid bool
+}
+
+// From implements dataparse.Fromer.
+func (o *%[1]s[T]) From(v dataparse.Value) error {
+	*o = %[1]s[T]{}
+	if v.IsNil() {
+		return nil
+	}
+	if s, ok := v.Data.(string); ok && strings.TrimSpace(s) == "" {
+		// empty strings are only valid strings
+		if _, ok := any(o.Value).(string); !ok {
+			return nil
+		}
+	}
+	if err := v.To(&o.Value); err != nil {
+		return err
+	}
+	o.Valid = true
+	return nil
+}
+`
+
+// optionalType returns the name of the generic type of fields that can
+// be missing, nil or empty, declaring it on first use.
+func (g *structGen) optionalType() string {
+	if g.optional == "" {
+		g.optional = unique(g.names, "Optional")
+		g.imports["strings"] = true
+		g.imports["github.com/ntnn/dataparse"] = true
+	}
+	return g.optional
+}
+
+// scalarType returns the Go type the values of field can be converted
+// to, preferring numbers over booleans and times over strings.
+func (g *structGen) scalarType(field *dataparse.FieldSchema) string {
+	switch {
+	case field.Can(dataparse.CoercionInt64):
+		return "int64"
+	case field.Can(dataparse.CoercionFloat64):
+		return "float64"
+	case field.Can(dataparse.CoercionBool):
+		return "bool"
+	case field.Can(dataparse.CoercionTime):
+		g.imports["time"] = true
+		return "time.Time"
+	}
+
+	for goType := range field.GoTypes {
+		if goType != "nil" && goType != "[]uint8" {
+			return "string"
+		}
+	}
+	if _, ok := field.GoTypes["[]uint8"]; ok {
+		return "[]byte"
+	}
+	return "string"
+}
+
+// structTag returns the struct tag mapping a field to key.
+func structTag(key string) string {
+	tag := "dataparse:" + strconv.Quote(key)
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// initialisms are written in upper case in Go names, e.g. user_id
+// becomes UserID.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "CSV": true, "DNS": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "TCP": true, "TLS": true, "UDP": true, "URI": true,
+	"URL": true, "UUID": true, "XML": true,
+}
+
+// goName returns an exported Go identifier for key, e.g. FirstName for
+// first_name.
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) || !token.IsExported(name) {
+		name = "Field" + name
+	}
+	return name
+}
+
+// unique returns name, or name with the lowest number appended that is
+// not yet in names, and adds it to names.
+func unique(names map[string]bool, name string) string {
+	candidate := name
+	for i := 2; names[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	names[candidate] = true
+	return candidate
+}
+
+// singular returns the name for the elements of a list named name,
+// e.g. RecordOrder for RecordOrders.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ss"):
+		return name + "Item"
+	case strings.HasSuffix(name, "s"):
+		return strings.TrimSuffix(name, "s")
+	default:
+		return name + "Item"
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ntnn/dataparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenStruct(t *testing.T) {
+	input := `{"user_id": 1, "name": "alice", "created": "2026-01-02T03:04:05Z", "address": {"city": "Berlin", "zip": "10115"}, "orders": [{"id": 1, "total": 1.5}], "tags": ["a"], "active": true}
+{"user_id": 2, "name": null, "created": "2026-01-03T03:04:05Z", "orders": [], "tags": null, "active": "no"}
+`
+	assert.Equal(t, `package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ntnn/dataparse"
+)
+
+type User struct {
+	Active  bool                  `+"`dataparse:\"active\"`"+`
+	Address Optional[UserAddress] `+"`dataparse:\"address\"`"+`
+	Created time.Time             `+"`dataparse:\"created\"`"+`
+	Name    Optional[string]      `+"`dataparse:\"name\"`"+`
+	Orders  []UserOrder           `+"`dataparse:\"orders\"`"+`
+	Tags    Optional[[]string]    `+"`dataparse:\"tags\"`"+`
+	UserID  int64                 `+"`dataparse:\"user_id\"`"+`
+}
+
+type UserAddress struct {
+	City string `+"`dataparse:\"city\"`"+`
+	Zip  int64  `+"`dataparse:\"zip\"`"+`
+}
+
+type UserOrder struct {
+	ID    int64   `+"`dataparse:\"id\"`"+`
+	Total float64 `+"`dataparse:\"total\"`"+`
+}
+
+`+fmt.Sprintf(optionalSource, "Optional"), runTest(t, input, "gen-struct", "-package", "models", "-name", "User"))
+}
+
+func TestGenStruct_Csv(t *testing.T) {
+	input := `id,name,score,city
+1,alice,1.5,Berlin
+2,bob,,
+3,carol,2,
+`
+	assert.Equal(t, "type Record struct {\n"+
+		"\tCity  string            `dataparse:\"city\"`\n"+
+		"\tID    int64             `dataparse:\"id\"`\n"+
+		"\tName  string            `dataparse:\"name\"`\n"+
+		"\tScore Optional[float64] `dataparse:\"score\"`\n"+
+		"}\n\n"+
+		fmt.Sprintf(optionalSource, "Optional"),
+		runTest(t, input, "gen-struct"),
+	)
+}
+
+// Optional is the type generated by gen-struct, see optionalSource.
+type Optional[T any] struct {
+	Value T
+	Valid bool
+}
+
+func (o *Optional[T]) From(v dataparse.Value) error {
+	*o = Optional[T]{}
+	if v.IsNil() {
+		return nil
+	}
+	if s, ok := v.Data.(string); ok && strings.TrimSpace(s) == "" {
+		if _, ok := any(o.Value).(string); !ok {
+			return nil
+		}
+	}
+	if err := v.To(&o.Value); err != nil {
+		return err
+	}
+	o.Valid = true
+	return nil
+}
+
+func TestGenStruct_Decode(t *testing.T) {
+	// the struct generated in TestGenStruct_Csv
+	type Record struct {
+		City  string            `dataparse:"city"`
+		ID    int64             `dataparse:"id"`
+		Name  string            `dataparse:"name"`
+		Score Optional[float64] `dataparse:"score"`
+	}
+
+	records := []Record{}
+	for m, err := range dataparse.ReaderAll(strings.NewReader("id,name,score,city\n1,alice,1.5,Berlin\n2,bob,,\n")) {
+		require.Nil(t, err)
+		var r Record
+		require.Nil(t, m.To(&r))
+		records = append(records, r)
+	}
+
+	assert.Equal(t, []Record{
+		{City: "Berlin", ID: 1, Name: "alice", Score: Optional[float64]{Value: 1.5, Valid: true}},
+		{ID: 2, Name: "bob"},
+	}, records)
+
+	// nil values and missing keys are not valid, empty strings are
+	// valid strings
+	var r struct {
+		Name  Optional[string]          `dataparse:"name"`
+		Empty Optional[string]          `dataparse:"empty"`
+		Tags  Optional[[]string]        `dataparse:"tags"`
+		Addr  Optional[struct{ A int }] `dataparse:"addr"`
+	}
+	m, err := dataparse.NewMap(map[string]any{"name": nil, "empty": "", "tags": nil})
+	require.Nil(t, err)
+	require.Nil(t, m.To(&r, dataparse.WithIgnoreNoValidKeyError()))
+	assert.False(t, r.Name.Valid)
+	assert.Equal(t, Optional[string]{Valid: true}, r.Empty)
+	assert.False(t, r.Tags.Valid)
+	assert.False(t, r.Addr.Valid)
+}
+
+func TestGenStruct_InvalidName(t *testing.T) {
+	assert.NotNil(t, run([]string{"gen-struct", "-name", "record"}, bytes.NewReader(nil), &bytes.Buffer{}))
+}
+
+func TestGoName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"first_name":   "FirstName",
+		"user-id":      "UserID",
+		"address.city": "AddressCity",
+		"ipAddress":    "IpAddress",
+		"2nd":          "Field2nd",
+		"":             "Field",
+		"_":            "Field",
+	} {
+		assert.Equal(t, expected, goName(key), key)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	assert.Equal(t, "RecordOrder", singular("RecordOrders"))
+	assert.Equal(t, "RecordCategory", singular("RecordCategories"))
+	assert.Equal(t, "RecordAddressItem", singular("RecordAddress"))
+	assert.Equal(t, "RecordDataItem", singular("RecordData"))
+}
+
+func TestStructTag(t *testing.T) {
+	assert.Equal(t, "`dataparse:\"a b\"`", structTag("a b"))
+	assert.Equal(t, `"dataparse:\"a`+"`"+`b\""`, structTag("a`b"))
+}
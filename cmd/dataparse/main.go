@@ -0,0 +1,413 @@
+// Command dataparse reads files in any format supported by dataparse.From
+// to convert them and to take quick looks at them.
+//
+// Usage:
+//
+//	dataparse convert [flags] <input> <output>
+//	dataparse head [flags] [input]
+//	dataparse count [flags] [input]
+//	dataparse get [flags] <path> [input]
+//	dataparse schema [flags] [input]
+//	dataparse gen-struct [flags] [input]
+//
+// The input "-" or no input reads from stdin, detecting the compression
+// and format from the content. The output "-" writes to stdout.
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"iter"
+	"log"
+	"math/big"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ntnn/dataparse"
+)
+
+func main() {
+	log.SetFlags(0)
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		log.Fatal(err)
+	}
+}
+
+const usage = `Usage: dataparse <command> [flags] [args]
+
+Commands:
+  convert <input> <output>  convert the input to the format of the output
+  head [input]              print the first records as NDJSON
+  count [input]             print the number of records
+  get <path> [input]        print the values at a dotted path, e.g. a.b.c
+  schema [input]            print the inferred types of the keys
+  gen-struct [input]        print a Go struct for the records
+
+The input "-" or no input reads from stdin, the output "-" writes to
+stdout. Run "dataparse <command> -h" for the flags of a command.
+`
+
+type command struct {
+	name   string
+	flags  *flag.FlagSet
+	stdin  io.Reader
+	stdout io.Writer
+
+	format    string
+	separator string
+}
+
+var commands = map[string]func(cmd *command, args []string) error{
+	"convert": runConvert,
+	"head":    runHead,
+	"count":   runCount,
+	"get":     runGet,
+	"schema":  runSchema,
+
+	"gen-struct": runGenStruct,
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		fmt.Fprint(stdout, usage)
+		return nil
+	}
+
+	fn, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+
+	cmd := &command{
+		name:   args[0],
+		flags:  flag.NewFlagSet(args[0], flag.ContinueOnError),
+		stdin:  stdin,
+		stdout: stdout,
+	}
+	cmd.flags.SetOutput(stdout)
+	cmd.flags.StringVar(&cmd.format, "format", "",
+		"format of the input, defaults to the extension or the detected format ("+strings.Join(dataparse.Formats(), ", ")+")")
+	cmd.flags.StringVar(&cmd.separator, "separator", "", "separator of delimited input like CSV")
+	return fn(cmd, args[1:])
+}
+
+// parse parses the flags and returns the positional arguments.
+// min and max are the allowed number of positional arguments.
+func (cmd *command) parse(args []string, usage string, min, max int) ([]string, error) {
+	cmd.flags.Usage = func() {
+		fmt.Fprintf(cmd.flags.Output(), "Usage: dataparse %s [flags] %s\n\nFlags:\n", cmd.name, usage)
+		cmd.flags.PrintDefaults()
+	}
+	if err := cmd.flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	positional := cmd.flags.Args()
+	if len(positional) < min || len(positional) > max {
+		cmd.flags.Usage()
+		return nil, fmt.Errorf("%s: expected %s", cmd.name, usage)
+	}
+	return positional, nil
+}
+
+// input returns the records of the input at path, reading from stdin
+// for "-" or an empty path.
+func (cmd *command) input(path string) iter.Seq2[*dataparse.Map, error] {
+	opts := []dataparse.FromOption{}
+	if cmd.format != "" {
+		opts = append(opts, dataparse.WithFormat(cmd.format))
+	}
+	if cmd.separator != "" {
+		opts = append(opts, dataparse.WithSeparator(cmd.separator))
+	}
+
+	if path == "" || path == "-" {
+		return dataparse.ReaderAll(cmd.stdin, opts...)
+	}
+	return dataparse.All(path, opts...)
+}
+
+func arg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+func runConvert(cmd *command, args []string) error {
+	to := cmd.flags.String("to", "", "format of the output, defaults to the extension or ndjson for stdout ("+strings.Join(dataparse.Writers(), ", ")+")")
+	headers := cmd.flags.String("headers", "", "comma separated columns of CSV output, defaults to the keys of the first record")
+	args, err := cmd.parse(args, "<input> <output>", 2, 2)
+	if err != nil {
+		return err
+	}
+
+	opts := []dataparse.FromOption{}
+	if *headers != "" {
+		opts = append(opts, dataparse.WithHeaders(strings.Split(*headers, ",")...))
+	}
+
+	seq := cmd.input(args[0])
+	if args[1] == "-" {
+		return dataparse.ToWriter(cmd.stdout, cmp.Or(*to, "ndjson"), seq, opts...)
+	}
+	if *to != "" {
+		opts = append(opts, dataparse.WithFormat(*to))
+	}
+	return dataparse.To(args[1], seq, opts...)
+}
+
+func runHead(cmd *command, args []string) error {
+	n := cmd.flags.Int("n", 10, "number of records to print")
+	to := cmd.flags.String("to", "ndjson", "format of the output ("+strings.Join(dataparse.Writers(), ", ")+")")
+	args, err := cmd.parse(args, "[input]", 0, 1)
+	if err != nil {
+		return err
+	}
+
+	return dataparse.ToWriter(cmd.stdout, *to, limit(cmd.input(arg(args, 0)), *n))
+}
+
+// limit returns an iterator over the first n elements of seq.
+func limit(seq iter.Seq2[*dataparse.Map, error], n int) iter.Seq2[*dataparse.Map, error] {
+	return func(yield func(*dataparse.Map, error) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for m, err := range seq {
+			if !yield(m, err) {
+				return
+			}
+			i++
+			if i >= n {
+				return
+			}
+		}
+	}
+}
+
+func runCount(cmd *command, args []string) error {
+	args, err := cmd.parse(args, "[input]", 0, 1)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, err := range cmd.input(arg(args, 0)) {
+		if err != nil {
+			return err
+		}
+		count++
+	}
+	_, err = fmt.Fprintln(cmd.stdout, count)
+	return err
+}
+
+func runGet(cmd *command, args []string) error {
+	missing := cmd.flags.Bool("missing", false, "print an empty line for records without the path")
+	args, err := cmd.parse(args, "<path> [input]", 1, 2)
+	if err != nil {
+		return err
+	}
+
+	for m, err := range cmd.input(arg(args, 1)) {
+		if err != nil {
+			return err
+		}
+
+		v, err := m.Get(args[0])
+		if err != nil || v.IsNil() {
+			if *missing {
+				if _, err := fmt.Fprintln(cmd.stdout); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		s, err := valueString(v.Data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.Provenance, err)
+		}
+		if _, err := fmt.Fprintln(cmd.stdout, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valueString returns scalar values as strings and maps and lists as
+// JSON.
+func valueString(v any) (string, error) {
+	_, isMap := v.(*dataparse.Map)
+	if isMap || isList(v) || reflect.ValueOf(v).Kind() == reflect.Map {
+		b, err := json.Marshal(jsonValue(v))
+		return string(b), err
+	}
+	return dataparse.NewValue(v).String()
+}
+
+// isList returns true for slices except byte slices like net.IP.
+func isList(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8
+}
+
+// jsonValue returns v with maps keyed by strings so it can be encoded
+// by encoding/json, with decimals written as numbers like ToJson does.
+func jsonValue(v any) any {
+	switch typed := v.(type) {
+	case *dataparse.Map:
+		return jsonValue(typed.Data)
+	case map[any]any:
+		m := make(map[string]any, len(typed))
+		for key, value := range typed {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case map[string]any:
+		m := make(map[string]any, len(typed))
+		for key, value := range typed {
+			m[key] = jsonValue(value)
+		}
+		return m
+	case *big.Rat:
+		s, err := dataparse.NewValue(typed).String()
+		if err != nil {
+			return typed
+		}
+		return json.Number(s)
+	}
+
+	if isList(v) && !reflect.ValueOf(v).IsNil() {
+		rv := reflect.ValueOf(v)
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = jsonValue(rv.Index(i).Interface())
+		}
+		return list
+	}
+	return v
+}
+
+func runSchema(cmd *command, args []string) error {
+	examples := cmd.flags.Int("examples", 3, "number of example values per key")
+	args, err := cmd.parse(args, "[input]", 0, 1)
+	if err != nil {
+		return err
+	}
+
+	schema, err := dataparse.InferSchema(cmd.input(arg(args, 0)), dataparse.WithSchemaExamples(*examples))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tTYPES\tJSON\tNULLABLE\tCOERCIONS\tDISTINCT\tEXAMPLES")
+	printSchema(w, "", schema)
+	return w.Flush()
+}
+
+// printSchema prints a line for each field of schema and its nested
+// fields, with the keys of the fields prefixed by prefix.
+func printSchema(w io.Writer, prefix string, schema *dataparse.Schema) {
+	for _, field := range schema.Fields {
+		printField(w, prefix+field.Key, field)
+	}
+}
+
+// printField prints a line for field under the given key, followed by
+// the lines of nested fields and list items.
+func printField(w io.Writer, key string, field *dataparse.FieldSchema) {
+	nullable := "no"
+	if field.Nullable() {
+		nullable = "yes"
+	}
+
+	coercions := make([]string, len(field.Coercions))
+	for i, c := range field.Coercions {
+		coercions[i] = string(c)
+		if c == dataparse.CoercionTime && field.TimeFormat != "" {
+			coercions[i] += " (" + timeFormatName(field.TimeFormat) + ")"
+		}
+	}
+
+	examples := make([]string, len(field.Examples))
+	for i, example := range field.Examples {
+		examples[i], _ = valueString(example)
+	}
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+		key,
+		typeCounts(field.GoTypes),
+		typeCounts(field.JSONTypes),
+		nullable,
+		strings.Join(coercions, ", "),
+		field.Cardinality,
+		strings.Join(examples, ", "),
+	)
+
+	if field.Fields != nil {
+		printSchema(w, key+".", field.Fields)
+	}
+	if field.Items != nil {
+		printField(w, key+"[]", field.Items)
+	}
+}
+
+// typeCounts returns the sorted names of types, with the number of
+// values if there is more than one type.
+func typeCounts(types map[string]int) string {
+	names := make([]string, 0, len(types))
+	for name, count := range types {
+		if len(types) > 1 {
+			name = fmt.Sprintf("%s (%d)", name, count)
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+var timeFormatNames = map[string]string{
+	time.RFC3339:     "RFC3339",
+	time.RFC3339Nano: "RFC3339Nano",
+	time.DateTime:    "DateTime",
+	time.DateOnly:    "DateOnly",
+	time.TimeOnly:    "TimeOnly",
+	time.ANSIC:       "ANSIC",
+	time.UnixDate:    "UnixDate",
+	time.RFC822:      "RFC822",
+	time.RFC822Z:     "RFC822Z",
+	time.RFC850:      "RFC850",
+	time.RFC1123:     "RFC1123",
+	time.RFC1123Z:    "RFC1123Z",
+	time.Layout:      "Layout",
+	time.RubyDate:    "RubyDate",
+	time.Kitchen:     "Kitchen",
+	time.Stamp:       "Stamp",
+	time.StampMilli:  "StampMilli",
+	time.StampMicro:  "StampMicro",
+	time.StampNano:   "StampNano",
+}
+
+// timeFormatName returns the name of the time package constant for
+// layout or the layout itself.
+func timeFormatName(layout string) string {
+	if name, ok := timeFormatNames[layout]; ok {
+		return name
+	}
+	return layout
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ntnn/dataparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCsv = `id,name,address.city
+1,alice,Berlin
+2,bob,Hamburg
+3,carol,
+`
+
+func runTest(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+	stdout := &bytes.Buffer{}
+	require.Nil(t, run(args, strings.NewReader(stdin), stdout))
+	return stdout.String()
+}
+
+func TestConvert(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.ndjson.gz")
+	runTest(t, testCsv, "convert", "-", out)
+
+	assert.Equal(t, "3\n", runTest(t, "", "count", out))
+	assert.Equal(t, "Berlin\nHamburg\n\n", runTest(t, "", "get", "address.city", out))
+}
+
+func TestConvert_Stdout(t *testing.T) {
+	in := filepath.Join(t.TempDir(), "in.csv")
+	require.Nil(t, os.WriteFile(in, []byte(testCsv), 0o600))
+
+	assert.Equal(t,
+		"address.city,id,name\nBerlin,1,alice\nHamburg,2,bob\n,3,carol\n",
+		runTest(t, "", "convert", "-to", "csv", in, "-"),
+	)
+}
+
+func TestHead(t *testing.T) {
+	assert.Equal(t,
+		`{"address.city":"Berlin","id":"1","name":"alice"}`+"\n",
+		runTest(t, testCsv, "head", "-n", "1"),
+	)
+}
+
+func TestGet(t *testing.T) {
+	input := `{"a": {"b": {"c": 1}}}
+{"a": {"b": {"c": [1, 2]}}}
+{"a": {}}
+`
+	assert.Equal(t, "1\n[1,2]\n", runTest(t, input, "get", "a.b.c", "-"))
+	assert.Equal(t, "1\n[1,2]\n\n", runTest(t, input, "get", "-missing", "a.b.c"))
+	assert.Equal(t, "{\"c\":1}\n{\"c\":[1,2]}\n", runTest(t, input, "get", "a.b"))
+}
+
+func TestValueString(t *testing.T) {
+	nested, err := dataparse.NewMap(map[string]any{"a": 1})
+	require.Nil(t, err)
+
+	for expected, v := range map[string]any{
+		"text":                   "text",
+		"12.5":                   big.NewRat(25, 2),
+		"192.168.1.1":            net.ParseIP("192.168.1.1"),
+		`{"1":0.25,"m":{"a":1}}`: map[any]any{1: big.NewRat(1, 4), "m": nested},
+		`["a","b"]`:              []string{"a", "b"},
+	} {
+		s, err := valueString(v)
+		require.Nil(t, err)
+		assert.Equal(t, expected, s)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	assert.Equal(t, `KEY           TYPES   JSON    NULLABLE  COERCIONS       DISTINCT  EXAMPLES
+address.city  string  string  yes                       2         Berlin, Hamburg
+id            string  string  no        Int64, Float64  3         1, 2, 3
+name          string  string  no                        3         alice, bob, carol
+`, runTest(t, testCsv, "schema"))
+}
+
+func TestSchema_Nested(t *testing.T) {
+	input := `{"a": {"b": [{"c": "2026-01-02T03:04:05Z"}]}}`
+	assert.Equal(t, `KEY      TYPES           JSON    NULLABLE  COERCIONS       DISTINCT  EXAMPLES
+a        map[string]any  object  no                        0         
+a.b      []any           array   no                        0         
+a.b[]    map[string]any  object  no                        0         
+a.b[].c  string          string  no        Time (RFC3339)  1         2026-01-02T03:04:05Z
+`, runTest(t, input, "schema", "-examples", "1"))
+}
+
+func TestRun_Errors(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	assert.NotNil(t, run([]string{"unknown"}, nil, stdout))
+	assert.NotNil(t, run([]string{"get"}, nil, stdout))
+	assert.NotNil(t, run([]string{"convert", "-", "out.unknown"}, strings.NewReader(testCsv), stdout))
+}
@@ -0,0 +1,69 @@
+package dataparse
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+func init() {
+	for _, ext := range []string{".gz", ".tgz"} {
+		// .tgz leaves no extension and the tar archive is detected
+		// from the content
+		RegisterDecompressor(ext, func(reader io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(reader)
+		})
+	}
+	RegisterDecompressorMagic(".gz", []byte{0x1f, 0x8b})
+
+	RegisterDecompressor(".bz2", func(reader io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(bzip2.NewReader(reader)), nil
+	})
+	// "BZh" is followed by the block size from 1 to 9, which keeps
+	// text starting with "BZh" from being detected as bzip2
+	for size := byte('1'); size <= '9'; size++ {
+		RegisterDecompressorMagic(".bz2", []byte{'B', 'Z', 'h', size})
+	}
+
+	RegisterDecompressor(".xz", func(reader io.Reader) (io.ReadCloser, error) {
+		xzReader, err := xz.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(xzReader), nil
+	})
+	RegisterDecompressorMagic(".xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00})
+
+	for _, ext := range []string{".zst", ".zstd"} {
+		RegisterDecompressor(ext, func(reader io.Reader) (io.ReadCloser, error) {
+			zstdReader, err := zstd.NewReader(reader)
+			if err != nil {
+				return nil, err
+			}
+			return zstdReader.IOReadCloser(), nil
+		})
+	}
+	RegisterDecompressorMagic(".zst", []byte{0x28, 0xb5, 0x2f, 0xfd})
+
+	RegisterDecompressor(".lz4", func(reader io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(lz4.NewReader(reader)), nil
+	})
+	RegisterDecompressorMagic(".lz4", []byte{0x04, 0x22, 0x4d, 0x18})
+
+	for _, ext := range []string{".zz", ".zlib"} {
+		RegisterDecompressor(ext, func(reader io.Reader) (io.ReadCloser, error) {
+			return zlib.NewReader(reader)
+		})
+	}
+	// zlib has no magic number, the header is identified by the
+	// compression method deflate and the common compression levels.
+	// 0x78 0x5e is omitted as it is printable text ("x^").
+	for _, level := range []byte{0x01, 0x9c, 0xda} {
+		RegisterDecompressorMagic(".zz", []byte{0x78, level})
+	}
+}
@@ -0,0 +1,99 @@
+package dataparse
+
+import (
+	"compress/zlib"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+func TestFrom_Decompressors(t *testing.T) {
+	csvData, err := os.ReadFile("./testdata/data.csv")
+	require.Nil(t, err)
+	tsvData := strings.ReplaceAll(string(csvData), ",", "\t")
+
+	compressors := map[string]func(io.Writer) (io.WriteCloser, error){
+		".xz": func(w io.Writer) (io.WriteCloser, error) {
+			return xz.NewWriter(w)
+		},
+		".zst": func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		},
+		".lz4": func(w io.Writer) (io.WriteCloser, error) {
+			return lz4.NewWriter(w), nil
+		},
+		".zz": func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+	}
+
+	dir := t.TempDir()
+	for ext, compress := range compressors {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(dir, "data.tsv"+ext)
+
+			f, err := os.Create(path)
+			require.Nil(t, err)
+			w, err := compress(f)
+			require.Nil(t, err)
+			_, err = w.Write([]byte(tsvData))
+			require.Nil(t, err)
+			require.Nil(t, w.Close())
+			require.Nil(t, f.Close())
+
+			// detected by extension, .tsv defaults to tab as separator
+			count := 0
+			for m, err := range All(path) {
+				require.Nil(t, err)
+				require.True(t, m.Has("first_name"))
+				count++
+			}
+			assert.Equal(t, 10, count)
+
+			// detected by magic number
+			count = 0
+			for m, err := range All(path, WithFormat(FormatAuto)) {
+				require.Nil(t, err)
+				require.True(t, m.Has("first_name"))
+				count++
+			}
+			assert.Equal(t, 10, count)
+		})
+	}
+}
+
+func TestFrom_Bzip2(t *testing.T) {
+	// there is no bzip2 compressor in the standard library, the file
+	// was created with bzip2 from data.csv
+	for _, opts := range [][]FromOption{
+		// detected by extension
+		nil,
+		// detected by magic number
+		{WithFormat(FormatAuto)},
+	} {
+		count := 0
+		for m, err := range All("./testdata/data.csv.bz2", opts...) {
+			require.Nil(t, err)
+			require.True(t, m.Has("first_name"))
+			count++
+		}
+		assert.Equal(t, 10, count)
+	}
+
+	// text starting with the magic without a block size is not bzip2
+	count := 0
+	for m, err := range ReaderAll(strings.NewReader("BZhello,world\n1,2\n")) {
+		require.Nil(t, err)
+		assert.Equal(t, "1", m.MustGet("BZhello").MustString())
+		count++
+	}
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,122 @@
+package dataparse
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect(t *testing.T, seq func(func(*Map, error) bool)) ([]int, []error) {
+	t.Helper()
+	ids := []int{}
+	errs := []error{}
+	for m, err := range seq {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ids = append(ids, m.MustGet("id").MustInt())
+	}
+	return ids, errs
+}
+
+const malformedCsv = `id,name
+1,a
+2,b,extra
+3,c"d
+4,"unterminated
+5,e
+6,"multi
+line"
+7
+8,f
+9,"open
+`
+
+func TestErrorPolicy_CsvStop(t *testing.T) {
+	ids, errs := collect(t, CsvAll(strings.NewReader(malformedCsv)))
+	assert.Equal(t, []int{1}, ids)
+	require.Len(t, errs, 1)
+}
+
+func TestErrorPolicy_CsvSkip(t *testing.T) {
+	ids, errs := collect(t, CsvAll(strings.NewReader(malformedCsv), WithErrorPolicy(ErrorPolicySkip)))
+	assert.Equal(t, []int{1, 3, 5, 6, 8}, ids)
+	require.Len(t, errs, 4)
+
+	lines := []int{}
+	for _, err := range errs {
+		var errRecord ErrRecord
+		require.True(t, errors.As(err, &errRecord))
+		lines = append(lines, errRecord.Line)
+	}
+	// wrong number of fields, unterminated quotes swallowing the
+	// following lines, wrong number of fields and unterminated quote
+	// until the end of the input
+	assert.Equal(t, []int{3, 5, 9, 11}, lines)
+	assert.ErrorIs(t, errs[1], csv.ErrQuote)
+	assert.ErrorIs(t, errs[3], csv.ErrQuote)
+}
+
+func TestErrorPolicy_CsvQuotes(t *testing.T) {
+	results := []*Map{}
+	for m, err := range CsvAll(strings.NewReader(malformedCsv), WithErrorPolicy(ErrorPolicySkip)) {
+		if err == nil {
+			results = append(results, m)
+		}
+	}
+	require.Len(t, results, 5)
+	assert.Equal(t, `c"d`, results[1].MustGet("name").MustString())
+	assert.Equal(t, "multi\nline", results[3].MustGet("name").MustString())
+	assert.Equal(t, 7, results[3].Provenance.Line)
+	assert.Equal(t, int64(48), results[3].Provenance.Offset)
+}
+
+func TestErrorPolicy_MaxErrors(t *testing.T) {
+	ids, errs := collect(t, CsvAll(strings.NewReader(malformedCsv),
+		WithErrorPolicy(ErrorPolicySkip), WithMaxErrors(2)))
+	assert.Equal(t, []int{1, 3}, ids)
+	require.Len(t, errs, 3)
+	require.ErrorIs(t, errs[2], ErrTooManyErrors)
+}
+
+const malformedNdjson = `{"id": 1}
+{"id": 2, "broken"}
+{"id": 3}
+{"id":
+4}
+[1]
+{"id": 6}    {"id": 7
+{"id": 8}`
+
+func TestErrorPolicy_NdjsonStop(t *testing.T) {
+	ids, errs := collect(t, JsonAll(strings.NewReader(malformedNdjson)))
+	assert.Equal(t, []int{1}, ids)
+	require.Len(t, errs, 1)
+}
+
+func TestErrorPolicy_NdjsonSkip(t *testing.T) {
+	ids, errs := collect(t, JsonAll(strings.NewReader(malformedNdjson), WithErrorPolicy(ErrorPolicySkip)))
+	// the document split across lines is valid JSON
+	assert.Equal(t, []int{1, 3, 4, 6, 8}, ids)
+	require.Len(t, errs, 3)
+
+	offsets := []int64{}
+	for _, err := range errs {
+		var errRecord ErrRecord
+		require.True(t, errors.As(err, &errRecord))
+		offsets = append(offsets, errRecord.Offset)
+	}
+	assert.Equal(t, []int64{10, 51, 67}, offsets)
+
+	// the offsets are still correct after resynchronizing
+	for m, err := range JsonAll(strings.NewReader(malformedNdjson), WithErrorPolicy(ErrorPolicySkip)) {
+		if err == nil && m.MustGet("id").MustInt() == 8 {
+			assert.Equal(t, int64(strings.LastIndex(malformedNdjson, "{")), m.Provenance.Offset)
+		}
+	}
+}
@@ -9,6 +9,9 @@ var (
 	ErrValueIsNil        = errors.New("dataparse: value is nil")
 	ErrValueIsNotPointer = errors.New("dataparse: value is not pointer")
 	ErrValueCannotBeSet  = errors.New("dataparse: value cannot be set")
+	ErrNoResult          = errors.New("dataparse: no result")
+	ErrTooManyErrors     = errors.New("dataparse: too many errors")
+	ErrNoMatch           = errors.New("dataparse: line does not match pattern")
 )
 
 // ErrUnhandled is returned as an error if the underlying type is not
@@ -42,3 +45,24 @@ func (e ErrNoValidKey) Error() string {
 func (e ErrNoValidKey) Keys() []any {
 	return e.keys
 }
+
+// ErrRecord is returned by readers if a record could not be read.
+// It carries the provenance of the failed record.
+type ErrRecord struct {
+	Provenance
+	Err error
+}
+
+// NewErrRecord returns an ErrRecord with the given provenance and
+// error.
+func NewErrRecord(p Provenance, err error) ErrRecord {
+	return ErrRecord{Provenance: p, Err: err}
+}
+
+func (e ErrRecord) Error() string {
+	return fmt.Sprintf("dataparse: error reading %s: %v", e.Provenance, e.Err)
+}
+
+func (e ErrRecord) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,213 @@
+package dataparse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FormatFunc returns an iterator over the maps read from the reader.
+type FormatFunc func(io.Reader, ...FromOption) iter.Seq2[*Map, error]
+
+// DecompressorFunc returns a reader decompressing the passed reader.
+type DecompressorFunc func(io.Reader) (io.ReadCloser, error)
+
+type format struct {
+	name       string
+	extensions []string
+	fn         FormatFunc
+}
+
+var (
+	formats       = map[string]format{}
+	formatsByExt  = map[string]string{}
+	decompressors = map[string]DecompressorFunc{}
+)
+
+func init() {
+	RegisterFormat("json", []string{".json", ".ndjson", ".jsonl"}, JsonAll)
+	RegisterFormat("csv", []string{".csv"}, CsvAll)
+	RegisterFormat("tsv", []string{".tsv"}, func(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+		// Default to tab as separator for .tsv
+		return CsvAll(reader, append([]FromOption{WithSeparator("\t")}, opts...)...)
+	})
+	RegisterFormat("xlsx", []string{".xlsx"}, XlsxAll)
+	RegisterFormat("yaml", []string{".yaml", ".yml"}, YamlAll)
+	RegisterFormat("toml", []string{".toml"}, TomlAll)
+	RegisterFormat("ini", []string{".ini", ".cfg"}, IniAll)
+	RegisterFormat("properties", []string{".properties"}, PropertiesAll)
+	RegisterFormat("xml", []string{".xml"}, XmlAll)
+	// fixed-width text has no common extension and must be selected
+	// with WithFormat
+	RegisterFormat("fixedwidth", nil, FixedWidthAll)
+	// lines require a pattern, see WithPattern
+	RegisterFormat("lines", nil, LinesAll)
+	RegisterFormat("logfmt", []string{".logfmt"}, LogfmtAll)
+	RegisterFormat("msgpack", []string{".msgpack", ".mpk"}, MsgpackAll)
+	RegisterFormat("cbor", []string{".cbor"}, CborAll)
+	RegisterFormat("parquet", []string{".parquet"}, ParquetAll)
+	RegisterFormat("avro", []string{".avro"}, AvroAll)
+	RegisterFormat("html", []string{".html", ".htm"}, HtmlTableAll)
+	RegisterFormat("markdown", []string{".md", ".markdown"}, MarkdownTableAll)
+	RegisterFormat("zip", []string{".zip"}, ZipAll)
+	RegisterFormat("tar", []string{".tar"}, TarAll)
+
+	// xlsx files are zip archives and must be checked first
+	RegisterFormatSniffer("xlsx", sniffXlsx)
+	RegisterFormatSniffer("zip", sniffZip)
+	RegisterFormatSniffer("tar", sniffTar)
+	RegisterFormatSniffer("parquet", sniffParquet)
+	RegisterFormatSniffer("avro", sniffAvro)
+	RegisterFormatSniffer("json", sniffJson)
+	RegisterFormatSniffer("yaml", sniffYaml)
+	// HTML documents would also be detected as XML
+	RegisterFormatSniffer("html", sniffHtml)
+	RegisterFormatSniffer("xml", sniffXml)
+	// pipe tables would be detected as delimited text
+	RegisterFormatSniffer("markdown", sniffMarkdown)
+}
+
+// RegisterFormat registers a format under the given name.
+// From and All use the format for files with one of the extensions.
+//
+// Registering a name or extension again replaces the previous
+// registration, which allows to replace builtin formats.
+//
+// RegisterFormat is not safe for concurrent use and is intended to be
+// called from init functions.
+func RegisterFormat(name string, extensions []string, fn FormatFunc) {
+	if previous, ok := formats[name]; ok {
+		for _, ext := range previous.extensions {
+			if formatsByExt[ext] == name {
+				delete(formatsByExt, ext)
+			}
+		}
+	}
+
+	normalized := make([]string, len(extensions))
+	for i, ext := range extensions {
+		normalized[i] = normalizeExt(ext)
+		formatsByExt[normalized[i]] = name
+	}
+
+	formats[name] = format{
+		name:       name,
+		extensions: normalized,
+		fn:         fn,
+	}
+}
+
+// RegisterDecompressor registers a decompressor for the given
+// extension.
+// From and All unwrap files with the extension using the decompressor
+// and select the format based on the remaining extension, e.g.
+// data.csv.gz is decompressed and read as CSV.
+// Stacked extensions are unwrapped in order.
+//
+// RegisterDecompressor is not safe for concurrent use and is intended
+// to be called from init functions.
+func RegisterDecompressor(ext string, fn DecompressorFunc) {
+	decompressors[normalizeExt(ext)] = fn
+}
+
+// Formats returns the names of the registered formats.
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// open wraps cfg.reader with the decompressors matching the extensions
+// of name and returns the iterator of the format matching the
+// remaining extension.
+//
+// If a format was passed with WithFormat it is used instead of the
+// extension. If the format is FormatAuto or the extension does not
+// match any format the input is sniffed, see sniff.
+//
+// The readers in cfg are closed once the iterator is done.
+func (cfg *fromConfig) open(name string, opts ...FromOption) (iter.Seq2[*Map, error], error) {
+	ext := normalizeExt(filepath.Ext(name))
+	for cfg.format != FormatAuto {
+		decompress, ok := decompressors[ext]
+		if !ok {
+			break
+		}
+
+		if err := cfg.decompress(ext, decompress); err != nil {
+			return nil, err
+		}
+
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		ext = normalizeExt(filepath.Ext(name))
+	}
+
+	formatName := cfg.format
+	if formatName == "" {
+		formatName = formatsByExt[ext]
+	}
+
+	if formatName == "" || formatName == FormatAuto {
+		detected, detectedOpts, err := cfg.sniff()
+		if err != nil {
+			if ext != "" && cfg.format == "" {
+				err = fmt.Errorf("dataparse: unhandled file extension: %q: %w", ext, err)
+			}
+			return nil, errors.Join(err, cfg.Close())
+		}
+		formatName = detected
+		opts = append(detectedOpts, opts...)
+	}
+
+	f, ok := formats[formatName]
+	if !ok {
+		return nil, errors.Join(
+			fmt.Errorf("dataparse: unknown format: %q", formatName),
+			cfg.Close(),
+		)
+	}
+
+	return cfg.wrap(f.fn(cfg.reader, opts...)), nil
+}
+
+// decompress wraps cfg.reader with the decompressor.
+// On error the readers in cfg are closed.
+func (cfg *fromConfig) decompress(ext string, decompress DecompressorFunc) error {
+	reader, err := decompress(cfg.reader)
+	if err != nil {
+		return errors.Join(
+			fmt.Errorf("dataparse: error creating %s reader: %w", ext, err),
+			cfg.Close(),
+		)
+	}
+	cfg.reader = reader
+	cfg.closers = append(cfg.closers, reader.Close)
+	return nil
+}
+
+// wrap returns an iterator over seq that closes the readers in cfg
+// once it is done.
+func (cfg *fromConfig) wrap(seq iter.Seq2[*Map, error]) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		for m, err := range seq {
+			if !yield(m, err) {
+				return
+			}
+		}
+	})
+}
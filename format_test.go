@@ -0,0 +1,80 @@
+package dataparse
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func linesAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	return func(yield func(*Map, error) bool) {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			m := NewEmptyMap()
+			m.Data["line"] = scanner.Text()
+			if !yield(m, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("test-lines", []string{"TESTLINES"}, linesAll)
+	t.Cleanup(func() {
+		delete(formats, "test-lines")
+		delete(formatsByExt, ".testlines")
+	})
+	assert.Contains(t, Formats(), "test-lines")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.testlines.gz.gz")
+
+	f, err := os.Create(path)
+	require.Nil(t, err)
+	outer := gzip.NewWriter(f)
+	inner := gzip.NewWriter(outer)
+	_, err = inner.Write([]byte("a\nb\n"))
+	require.Nil(t, err)
+	require.Nil(t, inner.Close())
+	require.Nil(t, outer.Close())
+	require.Nil(t, f.Close())
+
+	lines := []string{}
+	for m, err := range All(path) {
+		require.Nil(t, err)
+		lines = append(lines, m.MustGet("line").MustString())
+	}
+	assert.Equal(t, []string{"a", "b"}, lines)
+}
+
+func TestRegisterFormat_Replace(t *testing.T) {
+	RegisterFormat("test-replace", []string{".a", ".b"}, linesAll)
+	RegisterFormat("test-replace", []string{".b"}, linesAll)
+	t.Cleanup(func() {
+		delete(formats, "test-replace")
+		delete(formatsByExt, ".b")
+	})
+
+	_, ok := formatsByExt[".a"]
+	assert.False(t, ok)
+	assert.Equal(t, "test-replace", formatsByExt[".b"])
+}
+
+func TestFrom_UnhandledExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	require.Nil(t, os.WriteFile(path, []byte{0x00, 0x01, 0x02, 0x03}, 0o600))
+
+	_, err := From(path)
+	require.ErrorContains(t, err, `unhandled file extension: ".bin"`)
+}
@@ -0,0 +1,349 @@
+package dataparse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/klauspost/compress/zstd"
+)
+
+// FromAvro returns maps read from the records of an Avro object
+// container file.
+//
+// Blocks are read and decompressed one after another, so memory usage
+// is proportional to the size of a block instead of the size of the
+// file. Blocks larger than 256 MiB before decompression are reported
+// as an error, the limit can be changed with WithAvroMaxBlockSize.
+// The null, deflate, snappy and zstandard codecs are supported.
+// Fields can be selected with WithProjection, the other fields are
+// skipped without being decoded.
+//
+// Values are returned with Go types matching their logical types:
+//   - timestamps and dates as time.Time in UTC
+//   - decimals as *big.Rat
+//   - arrays as []any and maps and records as map[string]any
+//   - enums as string
+//
+// Values of unions are returned without the wrapping of their type
+// name, null values are returned as nil.
+//
+// FromAvro is a wrapper around AvroAll, returning the results in
+// a channel.
+func FromAvro(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromAvro(cfg))
+}
+
+// FromAvroContext is FromAvro with the passed context, see WithContext.
+func FromAvroContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromAvro(reader, append(opts, WithContext(ctx))...)
+}
+
+// AvroAll returns an iterator over the maps read from the records of an
+// Avro object container file, see FromAvro.
+func AvroAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromAvro(cfg)
+}
+
+func fromAvro(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		reader := avro.NewReader(cfg.reader, 4096)
+
+		var header ocf.Header
+		reader.ReadVal(ocf.HeaderSchema, &header)
+		if reader.Error != nil {
+			yield(nil, fmt.Errorf("dataparse: error reading avro header: %w", reader.Error))
+			return
+		}
+		if header.Magic != [4]byte{'O', 'b', 'j', 1} {
+			yield(nil, errors.New("dataparse: not an avro object container file"))
+			return
+		}
+
+		// a cache per file as named types of different files may differ
+		schema, err := avro.ParseBytesWithCache(header.Meta["avro.schema"], "", &avro.SchemaCache{})
+		if err != nil {
+			yield(nil, fmt.Errorf("dataparse: error parsing avro schema: %w", err))
+			return
+		}
+
+		schema, err = avroProjection(schema, cfg.projection)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		decompress, err := avroCodec(cfg, string(header.Meta["avro.codec"]))
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		r := &avroReader{
+			cfg:        cfg,
+			yield:      yield,
+			reader:     reader,
+			schema:     schema,
+			sync:       header.Sync,
+			decompress: decompress,
+		}
+		for {
+			block, count, err := r.block()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, NewErrRecord(r.provenance(), err))
+				}
+				return
+			}
+			if !r.records(block, count) {
+				return
+			}
+		}
+	})
+}
+
+// avroProjection returns the schema to decode records with, which is
+// the writer schema resolved against a reader schema with only the
+// projected fields.
+// Without projection the writer schema is returned as is.
+func avroProjection(writer avro.Schema, projection []string) (avro.Schema, error) {
+	if len(projection) == 0 {
+		return writer, nil
+	}
+
+	record, ok := writer.(*avro.RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("dataparse: projection requires a record schema, got %s", writer.Type())
+	}
+
+	fields := map[string]*avro.Field{}
+	for _, field := range record.Fields() {
+		fields[field.Name()] = field
+	}
+
+	projected := make([]*avro.Field, 0, len(projection))
+	for _, name := range projection {
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("dataparse: projected field %q not found in avro schema", name)
+		}
+		field, err := avro.NewField(name, field.Type())
+		if err != nil {
+			return nil, fmt.Errorf("dataparse: error projecting avro field %q: %w", name, err)
+		}
+		projected = append(projected, field)
+	}
+
+	reader, err := avro.NewRecordSchema(record.Name(), record.Namespace(), projected)
+	if err != nil {
+		return nil, fmt.Errorf("dataparse: error projecting avro schema: %w", err)
+	}
+
+	resolved, err := avro.NewSchemaCompatibility().Resolve(reader, writer)
+	if err != nil {
+		return nil, fmt.Errorf("dataparse: error projecting avro schema: %w", err)
+	}
+	return resolved, nil
+}
+
+// avroCodec returns the function decompressing blocks of the codec.
+// Decoders holding resources are closed with the readers of cfg.
+func avroCodec(cfg *fromConfig, name string) (func([]byte) ([]byte, error), error) {
+	switch ocf.CodecName(name) {
+	case "", ocf.Null:
+		return (&ocf.NullCodec{}).Decode, nil
+	case ocf.Deflate:
+		return (&ocf.DeflateCodec{}).Decode, nil
+	case ocf.Snappy:
+		return (&ocf.SnappyCodec{}).Decode, nil
+	case ocf.ZStandard:
+		decoder, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		cfg.closers = append(cfg.closers, func() error {
+			decoder.Close()
+			return nil
+		})
+		return func(b []byte) ([]byte, error) {
+			return decoder.DecodeAll(b, nil)
+		}, nil
+	default:
+		return nil, fmt.Errorf("dataparse: unhandled avro codec %q", name)
+	}
+}
+
+// avroReader reads the blocks of an object container file.
+type avroReader struct {
+	cfg        *fromConfig
+	yield      func(*Map, error) bool
+	reader     *avro.Reader
+	schema     avro.Schema
+	sync       [16]byte
+	decompress func([]byte) ([]byte, error)
+	index      int
+}
+
+func (r *avroReader) provenance() Provenance {
+	return Provenance{
+		Source: r.cfg.source,
+		Member: r.cfg.member,
+		Index:  r.index,
+	}
+}
+
+// block returns the decompressed data of the next block and the number
+// of records in it. At the end of the file io.EOF is returned.
+func (r *avroReader) block() ([]byte, int64, error) {
+	_ = r.reader.Peek()
+	if r.reader.Error != nil {
+		return nil, 0, r.reader.Error
+	}
+
+	count := r.reader.ReadLong()
+	size := r.reader.ReadLong()
+	if r.reader.Error != nil {
+		return nil, 0, fmt.Errorf("dataparse: error reading avro block: %w",
+			noEOF(r.reader.Error))
+	}
+	if count < 0 {
+		return nil, 0, fmt.Errorf("dataparse: invalid avro block record count %d", count)
+	}
+	if size < 0 || size > r.cfg.avroMaxBlockSize {
+		return nil, 0, fmt.Errorf("dataparse: invalid avro block size %d, see WithAvroMaxBlockSize", size)
+	}
+	data := make([]byte, size)
+	r.reader.Read(data)
+
+	var sync [16]byte
+	r.reader.Read(sync[:])
+	if r.reader.Error != nil {
+		return nil, 0, fmt.Errorf("dataparse: error reading avro block: %w",
+			noEOF(r.reader.Error))
+	}
+	if sync != r.sync {
+		return nil, 0, errors.New("dataparse: invalid avro block sync marker")
+	}
+
+	data, err := r.decompress(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("dataparse: error decompressing avro block: %w", err)
+	}
+	return data, count, nil
+}
+
+// noEOF turns io.EOF into io.ErrUnexpectedEOF for truncated input.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// records yields the count records decoded from block.
+func (r *avroReader) records(block []byte, count int64) bool {
+	decoder := avro.NewDecoderForSchema(r.schema, bytes.NewReader(block))
+	for range count {
+		p := r.provenance()
+		r.index++
+
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			r.yield(nil, NewErrRecord(p, err))
+			return false
+		}
+
+		value = avroValue(r.schema, value)
+		m, err := NewMap(value)
+		if err != nil {
+			if !r.cfg.recordError(r.yield,
+				NewErrRecord(p, fmt.Errorf("dataparse: unhandled type %T: %w", value, err))) {
+				return false
+			}
+			continue
+		}
+		m.Provenance = p
+		if !r.yield(m, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// avroValue normalizes the decoded value v of schema.
+// Values of named types in unions are decoded wrapped in a map with the
+// name of the type as key, which is removed.
+func avroValue(schema avro.Schema, v any) any {
+	switch s := schema.(type) {
+	case *avro.RecordSchema:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		for _, field := range s.Fields() {
+			if value, ok := m[field.Name()]; ok {
+				m[field.Name()] = avroValue(field.Type(), value)
+			}
+		}
+		return m
+	case *avro.ArraySchema:
+		if list, ok := v.([]any); ok {
+			for i := range list {
+				list[i] = avroValue(s.Items(), list[i])
+			}
+		}
+		return v
+	case *avro.MapSchema:
+		if m, ok := v.(map[string]any); ok {
+			for key, value := range m {
+				m[key] = avroValue(s.Values(), value)
+			}
+		}
+		return v
+	case *avro.UnionSchema:
+		return avroUnion(s, v)
+	}
+
+	if t, ok := v.(time.Time); ok {
+		return t.UTC()
+	}
+	return v
+}
+
+func avroUnion(s *avro.UnionSchema, v any) any {
+	if wrapped, ok := v.(map[string]any); ok && len(wrapped) == 1 {
+		for _, typ := range s.Types() {
+			named, ok := typ.(avro.NamedSchema)
+			if !ok {
+				continue
+			}
+			if value, ok := wrapped[named.FullName()]; ok {
+				return avroValue(typ, value)
+			}
+		}
+	}
+
+	for _, typ := range s.Types() {
+		switch typ.Type() {
+		case avro.Array:
+			if _, ok := v.([]any); ok {
+				return avroValue(typ, v)
+			}
+		case avro.Map:
+			if _, ok := v.(map[string]any); ok {
+				return avroValue(typ, v)
+			}
+		}
+	}
+	return avroValue(nil, v)
+}
@@ -0,0 +1,211 @@
+package dataparse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2/ocf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const avroTestSchema = `{
+	"type": "record",
+	"name": "record",
+	"namespace": "test",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "name", "type": ["null", "string"]},
+		{"name": "price", "type": {"type": "bytes", "logicalType": "decimal", "precision": 9, "scale": 2}},
+		{"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "day", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "counts", "type": {"type": "map", "values": "int"}},
+		{"name": "address", "type": ["null", {
+			"type": "record",
+			"name": "address",
+			"fields": [{"name": "city", "type": "string"}]
+		}]},
+		{"name": "color", "type": {"type": "enum", "name": "color", "symbols": ["RED", "GREEN"]}}
+	]
+}`
+
+func avroTestRecord(id int64) map[string]any {
+	return map[string]any{
+		"id":      id,
+		"name":    map[string]any{"string": "alice"},
+		"price":   big.NewRat(1250, 100),
+		"created": time.Date(2026, 1, 2, 3, 4, 5, 6e6, time.UTC),
+		"day":     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		"tags":    []any{"a", "b"},
+		"counts":  map[string]any{"x": 1},
+		"address": map[string]any{"test.address": map[string]any{"city": "Berlin"}},
+		"color":   "GREEN",
+	}
+}
+
+func writeAvro(t *testing.T, codec ocf.CodecName, records ...map[string]any) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	// small blocks to read across block boundaries
+	enc, err := ocf.NewEncoder(avroTestSchema, buf, ocf.WithCodec(codec), ocf.WithBlockLength(2))
+	require.Nil(t, err)
+	for _, record := range records {
+		require.Nil(t, enc.Encode(record))
+	}
+	require.Nil(t, enc.Close())
+	return buf.Bytes()
+}
+
+func TestAvroAll(t *testing.T) {
+	second := avroTestRecord(2)
+	second["name"] = nil
+	second["address"] = nil
+
+	for _, codec := range []ocf.CodecName{ocf.Null, ocf.Deflate, ocf.Snappy, ocf.ZStandard} {
+		t.Run(string(codec), func(t *testing.T) {
+			input := writeAvro(t, codec, avroTestRecord(1), second, avroTestRecord(3))
+
+			results := []*Map{}
+			for m, err := range AvroAll(bytes.NewReader(input)) {
+				require.Nil(t, err)
+				results = append(results, m)
+			}
+			require.Len(t, results, 3)
+
+			first := results[0]
+			assert.Equal(t, int64(1), first.Data["id"])
+			assert.Equal(t, "alice", first.Data["name"])
+			assert.Equal(t, "12.5", first.MustGet("price").MustString())
+			assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 6e6, time.UTC), first.Data["created"])
+			assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), first.Data["day"])
+			assert.Equal(t, []any{"a", "b"}, first.Data["tags"])
+			assert.Equal(t, map[string]any{"x": 1}, first.Data["counts"])
+			assert.Equal(t, "Berlin", first.MustGet("address.city").MustString())
+			assert.Equal(t, "GREEN", first.Data["color"])
+
+			assert.Nil(t, results[1].Data["name"])
+			assert.Nil(t, results[1].Data["address"])
+
+			for i, m := range results {
+				assert.Equal(t, i, m.Provenance.Index)
+			}
+		})
+	}
+}
+
+func TestAvroAll_Projection(t *testing.T) {
+	input := writeAvro(t, ocf.Null, avroTestRecord(1), avroTestRecord(2))
+
+	results := []*Map{}
+	for m, err := range AvroAll(bytes.NewReader(input), WithProjection("address", "id")) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	require.Len(t, results, 2)
+
+	assert.Equal(t, map[any]any{
+		"id":      int64(2),
+		"address": map[string]any{"city": "Berlin"},
+	}, results[1].Data)
+}
+
+func TestAvroAll_ProjectionUnknownField(t *testing.T) {
+	input := writeAvro(t, ocf.Null, avroTestRecord(1))
+
+	for _, err := range AvroAll(bytes.NewReader(input), WithProjection("missing")) {
+		require.ErrorContains(t, err, `projected field "missing" not found`)
+	}
+}
+
+func TestAvroAll_Truncated(t *testing.T) {
+	input := writeAvro(t, ocf.Null, avroTestRecord(1), avroTestRecord(2), avroTestRecord(3))
+
+	var lastErr error
+	count := 0
+	for m, err := range AvroAll(bytes.NewReader(input[:len(input)-10])) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		require.NotNil(t, m)
+		count++
+	}
+	assert.Equal(t, 2, count)
+	require.ErrorIs(t, lastErr, io.ErrUnexpectedEOF)
+}
+
+func TestAvroAll_Corrupt(t *testing.T) {
+	header := writeAvro(t, ocf.Null)
+	sync := header[len(header)-16:]
+
+	for name, block := range map[string][]int64{
+		"negative size":  {1, -5},
+		"huge size":      {1, 1 << 62},
+		"negative count": {-1, 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			input := bytes.Clone(header)
+			for _, n := range block {
+				// avro longs are zigzag encoded varints
+				input = binary.AppendVarint(input, n)
+			}
+			input = append(input, sync...)
+
+			errs := []error{}
+			for m, err := range AvroAll(bytes.NewReader(input)) {
+				require.Nil(t, m)
+				errs = append(errs, err)
+			}
+			require.Len(t, errs, 1)
+			var errRecord ErrRecord
+			require.ErrorAs(t, errs[0], &errRecord)
+			assert.ErrorContains(t, errs[0], "invalid avro block")
+		})
+	}
+}
+
+func TestAvroAll_MaxBlockSize(t *testing.T) {
+	input := writeAvro(t, ocf.Null, avroTestRecord(1), avroTestRecord(2))
+
+	_, err := single(AvroAll(bytes.NewReader(input), WithAvroMaxBlockSize(10)))
+	assert.ErrorContains(t, err, "invalid avro block size")
+
+	count := 0
+	for _, err := range AvroAll(bytes.NewReader(input), WithAvroMaxBlockSize(1<<10)) {
+		require.Nil(t, err)
+		count++
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestFrom_Avro(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.avro")
+	require.Nil(t, os.WriteFile(path, writeAvro(t, ocf.Deflate, avroTestRecord(1)), 0o644))
+
+	m, err := single(All(path))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), m.Data["id"])
+	assert.Equal(t, path, m.Provenance.Source)
+}
+
+func TestFrom_SniffParquetAvro(t *testing.T) {
+	dir := t.TempDir()
+	avroPath := filepath.Join(dir, "data.bin")
+	require.Nil(t, os.WriteFile(avroPath, writeAvro(t, ocf.Null, avroTestRecord(1)), 0o644))
+	m, err := single(All(avroPath))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), m.Data["id"])
+
+	parquetPath := filepath.Join(dir, "data.dat")
+	require.Nil(t, os.WriteFile(parquetPath, writeParquet(t, parquetTestRecords()...), 0o644))
+	m, err = single(All(parquetPath))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), m.Data["id"])
+}
@@ -0,0 +1,277 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// FromCbor returns maps parsed from a CBOR stream which may consist of:
+// 1. A single document
+// 2. A stream of documents
+// 3. An array of documents, including indefinite-length arrays
+//
+// Arrays are decoded one element at a time like in FromJson.
+//
+// Values keep their CBOR types: byte strings are returned as []byte,
+// timestamps tagged as date/time as time.Time, positive integers as
+// uint64 and negative integers as int64. Maps with only string keys
+// are returned as map[string]any, other maps as map[any]any.
+//
+// FromCbor is a wrapper around CborAll, returning the results in
+// a channel.
+func FromCbor(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromCbor(cfg))
+}
+
+// FromCborContext is FromCbor with the passed context, see WithContext.
+func FromCborContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromCbor(reader, append(opts, WithContext(ctx))...)
+}
+
+// CborAll returns an iterator over the maps parsed from a CBOR stream,
+// see FromCbor.
+func CborAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromCbor(cfg)
+}
+
+var cborDecMode = func() cbor.DecMode {
+	mode, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[any]any{}),
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return mode
+}()
+
+const (
+	cborMajorArray  = 4
+	cborIndefinite  = 31
+	cborBreak       = 0xff
+	cborMajorOffset = 5
+)
+
+func fromCbor(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		buffered := bufio.NewReader(cfg.reader)
+		r := &cborReader{
+			binaryReader: binaryReader{cfg: cfg, yield: yield},
+			buffered:     buffered,
+			source:       &prefixReader{reader: buffered},
+		}
+		r.decoder = cborDecMode.NewDecoder(r.source)
+
+		for {
+			next, err := r.peek()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, err)
+				}
+				return
+			}
+
+			if next>>cborMajorOffset != cborMajorArray {
+				if !r.decode() {
+					return
+				}
+				continue
+			}
+
+			if !r.array() {
+				return
+			}
+		}
+	})
+}
+
+// cborReader reads maps from a CBOR stream.
+//
+// The decoder reads ahead, so the array headers are read from the
+// input after detaching the decoder, see detach.
+type cborReader struct {
+	binaryReader
+	buffered *bufio.Reader
+	source   *prefixReader
+	decoder  *cbor.Decoder
+	// consumed is the number of bytes consumed before the current
+	// decoder was created.
+	consumed int64
+}
+
+func (r *cborReader) offset() int64 {
+	return r.consumed + int64(r.decoder.NumBytesRead())
+}
+
+// peek returns the next byte in the stream without consuming it.
+func (r *cborReader) peek() (byte, error) {
+	c := make([]byte, 1)
+	if n, _ := r.decoder.Buffered().Read(c); n == 1 {
+		return c[0], nil
+	}
+	if len(r.source.prefix) > 0 {
+		return r.source.prefix[0], nil
+	}
+	peeked, err := r.buffered.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return peeked[0], nil
+}
+
+// detach moves the bytes buffered by the decoder back to the source,
+// so the source can be read directly.
+// A new decoder must be created with attach before decoding values.
+func (r *cborReader) detach() error {
+	rest, err := io.ReadAll(r.decoder.Buffered())
+	if err != nil {
+		return err
+	}
+	r.consumed = r.offset()
+	r.source = &prefixReader{
+		prefix: append(rest, r.source.prefix...),
+		reader: r.buffered,
+	}
+	return nil
+}
+
+// attach creates a new decoder for the source.
+func (r *cborReader) attach() {
+	r.decoder = cborDecMode.NewDecoder(r.source)
+}
+
+// read reads n bytes directly from the source.
+func (r *cborReader) read(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.source, b); err != nil {
+		return nil, err
+	}
+	r.consumed += int64(n)
+	return b, nil
+}
+
+// decode yields the next value of the decoder.
+func (r *cborReader) decode() bool {
+	offset := r.offset()
+	var value any
+	err := r.decoder.Decode(&value)
+	return r.record(offset, normalizeCborMaps(value), err)
+}
+
+// array yields the elements of the array at the start of the stream.
+func (r *cborReader) array() bool {
+	if err := r.detach(); err != nil {
+		r.yield(nil, err)
+		return false
+	}
+
+	length, indefinite, err := r.arrayHeader()
+	if err != nil {
+		r.yield(nil, NewErrRecord(r.provenance(r.consumed), err))
+		return false
+	}
+	r.attach()
+
+	if !indefinite {
+		for range length {
+			if !r.decode() {
+				return false
+			}
+		}
+		return true
+	}
+
+	for {
+		next, err := r.peek()
+		if err != nil {
+			r.yield(nil, NewErrRecord(r.provenance(r.offset()),
+				fmt.Errorf("dataparse: unterminated indefinite-length array: %w", err)))
+			return false
+		}
+
+		if next == cborBreak {
+			// consume the break
+			if err := r.detach(); err != nil {
+				r.yield(nil, err)
+				return false
+			}
+			if _, err := r.read(1); err != nil {
+				r.yield(nil, err)
+				return false
+			}
+			r.attach()
+			return true
+		}
+
+		if !r.decode() {
+			return false
+		}
+	}
+}
+
+// arrayHeader reads the header of an array from the source and returns
+// the length of the array or whether the array has an indefinite
+// length.
+func (r *cborReader) arrayHeader() (uint64, bool, error) {
+	b, err := r.read(1)
+	if err != nil {
+		return 0, false, err
+	}
+
+	info := b[0] & 0x1f
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+	case info == cborIndefinite:
+		return 0, true, nil
+	case info > 27:
+		return 0, false, fmt.Errorf("dataparse: malformed CBOR array header 0x%x", b[0])
+	}
+
+	// additional info 24 to 27 is followed by 1, 2, 4 or 8 bytes
+	size := 1 << (info - 24)
+	b, err = r.read(size)
+	if err != nil {
+		return 0, false, err
+	}
+	length := uint64(0)
+	switch size {
+	case 1:
+		length = uint64(b[0])
+	case 2:
+		length = uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		length = uint64(binary.BigEndian.Uint32(b))
+	case 8:
+		length = binary.BigEndian.Uint64(b)
+	}
+	return length, false, nil
+}
+
+// normalizeCborMaps converts maps with only string keys to
+// map[string]any.
+func normalizeCborMaps(value any) any {
+	switch typed := value.(type) {
+	case map[any]any:
+		for key, elem := range typed {
+			typed[key] = normalizeCborMaps(elem)
+		}
+		return stringKeys(typed)
+	case []any:
+		for i, elem := range typed {
+			typed[i] = normalizeCborMaps(elem)
+		}
+	}
+	return value
+}
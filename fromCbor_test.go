@@ -0,0 +1,93 @@
+package dataparse
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeCbor(t *testing.T, values ...any) []byte {
+	t.Helper()
+	mode, err := cbor.EncOptions{Time: cbor.TimeRFC3339, TimeTag: cbor.EncTagRequired}.EncMode()
+	require.Nil(t, err)
+	buf := &bytes.Buffer{}
+	enc := mode.NewEncoder(buf)
+	for _, v := range values {
+		require.Nil(t, enc.Encode(v))
+	}
+	return buf.Bytes()
+}
+
+func TestCborAll(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := encodeCbor(t,
+		map[string]any{"id": 1, "raw": []byte{0x01, 0x02}, "ts": ts, "neg": -5},
+		[]any{
+			map[string]any{"id": 2},
+			map[string]any{"id": 3, "nested": map[string]any{"a": "b"}},
+		},
+		map[any]any{4: "int key", "id": 4},
+	)
+
+	results := []*Map{}
+	for m, err := range CborAll(bytes.NewReader(input)) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	require.Len(t, results, 4)
+
+	assert.Equal(t, uint64(1), results[0].Data["id"])
+	assert.Equal(t, int64(-5), results[0].Data["neg"])
+	assert.Equal(t, []byte{0x01, 0x02}, results[0].Data["raw"])
+	assert.Equal(t, ts, results[0].MustGet("ts").MustTime())
+
+	assert.Equal(t, 2, results[1].MustGet("id").MustInt())
+	assert.Equal(t, "b", results[2].MustGet("nested.a").MustString())
+	assert.Equal(t, "int key", results[3].MustGet(uint64(4)).MustString())
+
+	first := int64(len(encodeCbor(t, map[string]any{"id": 1, "raw": []byte{0x01, 0x02}, "ts": ts, "neg": -5})))
+	assert.Equal(t, int64(0), results[0].Provenance.Offset)
+	// the array header is one byte
+	assert.Equal(t, first+1, results[1].Provenance.Offset)
+}
+
+func TestCborAll_IndefiniteArray(t *testing.T) {
+	elem := encodeCbor(t, map[string]any{"id": 1})
+	input := []byte{0x9f}
+	for range 3 {
+		input = append(input, elem...)
+	}
+	input = append(input, 0xff)
+	input = append(input, encodeCbor(t, map[string]any{"id": 2})...)
+
+	// arrays longer than 23 elements use a one byte length
+	long := []any{}
+	for range 30 {
+		long = append(long, map[string]any{"id": 3})
+	}
+	input = append(input, encodeCbor(t, long)...)
+
+	ids, errs := collect(t, CborAll(bytes.NewReader(input)))
+	assert.Empty(t, errs)
+	assert.Len(t, ids, 34)
+	assert.Equal(t, []int{1, 1, 1, 2, 3}, ids[:5])
+
+	_, errs = collect(t, CborAll(bytes.NewReader(input[:len(elem)*3+1])))
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "unterminated indefinite-length array")
+}
+
+func TestFrom_Cbor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.cbor")
+	require.Nil(t, os.WriteFile(path, encodeCbor(t, []any{map[string]any{"id": 1}}), 0o644))
+
+	m, err := FromSingle(path)
+	require.Nil(t, err)
+	assert.Equal(t, 1, m.MustGet("id").MustInt())
+}
@@ -0,0 +1,240 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"unicode/utf8"
+)
+
+// Column defines the position of a column in fixed-width text.
+// Start and End are character positions, Start is inclusive and End is
+// exclusive. An End of zero extends the column to the end of the line.
+type Column struct {
+	Name  string
+	Start int
+	End   int
+}
+
+// FromFixedWidth returns maps parsed from fixed-width text.
+//
+// The columns are defined with WithColumns, in which case every
+// non-empty line is a record.
+// Otherwise the columns are inferred from the first line, which is
+// expected to be a header: every header name starts a column which
+// extends to the start of the next header name.
+// If the second line is a ruler of dashes or equal signs separated by
+// spaces, e.g. "------ ---", the runs of the ruler define the columns
+// instead, which allows header names containing spaces.
+//
+// Values are trimmed unless disabled with WithTrimSpace. Lines shorter
+// than a column yield an empty value.
+//
+// FromFixedWidth is a wrapper around FixedWidthAll, returning the
+// results in a channel.
+func FromFixedWidth(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromFixedWidth(cfg))
+}
+
+// FromFixedWidthContext is FromFixedWidth with the passed context, see
+// WithContext.
+func FromFixedWidthContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromFixedWidth(reader, append(opts, WithContext(ctx))...)
+}
+
+// FixedWidthAll returns an iterator over the maps parsed from
+// fixed-width text, see FromFixedWidth.
+func FixedWidthAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromFixedWidth(cfg)
+}
+
+func fromFixedWidth(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		r := &lineReader{reader: bufio.NewReader(cfg.reader)}
+
+		columns := cfg.columns
+		if len(columns) == 0 {
+			var err error
+			columns, err = inferColumns(r)
+			if err != nil {
+				yield(nil, fmt.Errorf("dataparse: error inferring columns: %w", err))
+				return
+			}
+		}
+
+		for _, column := range columns {
+			if column.Start < 0 || (column.End != 0 && column.End <= column.Start) {
+				yield(nil, fmt.Errorf("dataparse: invalid column %q: start %d, end %d",
+					column.Name, column.Start, column.End))
+				return
+			}
+		}
+
+		for index := 0; ; {
+			line, err := r.next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, NewErrRecord(Provenance{
+						Source: cfg.source,
+						Member: cfg.member,
+						Index:  index,
+						Line:   r.line,
+						Offset: r.offset,
+					}, err))
+				}
+				return
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			m := NewEmptyMap()
+			for _, column := range columns {
+				value := substring(line, column.Start, column.End)
+				if cfg.trimSpace {
+					value = strings.TrimSpace(value)
+				}
+				m.Data[column.Name] = value
+			}
+			m.Provenance = Provenance{
+				Source: cfg.source,
+				Member: cfg.member,
+				Index:  index,
+				Line:   r.line,
+				Offset: r.offset,
+			}
+			index++
+			if !yield(m, nil) {
+				return
+			}
+		}
+	})
+}
+
+// lineReader reads lines and keeps track of the line number and offset
+// of the last line.
+type lineReader struct {
+	reader   *bufio.Reader
+	line     int
+	offset   int64
+	consumed int64
+}
+
+// next returns the next line without the line ending.
+func (r *lineReader) next() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if line == "" && err != nil {
+		return "", err
+	}
+	r.line++
+	r.offset = r.consumed
+	r.consumed += int64(len(line))
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, nil
+}
+
+// inferColumns reads the header line and the optional ruler line and
+// returns the columns.
+func inferColumns(r *lineReader) ([]Column, error) {
+	header, err := r.next()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header line")
+		}
+		return nil, err
+	}
+	if strings.TrimSpace(header) == "" {
+		return nil, errors.New("empty header line")
+	}
+
+	peeked, _ := r.reader.Peek(r.reader.Size())
+	if second, _, _ := strings.Cut(string(peeked), "\n"); isRuler(strings.TrimSuffix(second, "\r")) {
+		ruler, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		return columnsFromRuns(header, ruler, func(c rune) bool { return c == ' ' }), nil
+	}
+
+	return columnsFromRuns(header, header, func(c rune) bool { return c == ' ' || c == '\t' }), nil
+}
+
+// isRuler returns true if line consists of dashes or equal signs
+// separated by spaces.
+func isRuler(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+	for _, c := range line {
+		if c != '-' && c != '=' && c != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// columnsFromRuns returns a column for each run of characters in
+// layout that are not spaces. Each column extends to the start of the
+// next run, the last column to the end of the line.
+// The column names are taken from the header at the column positions.
+func columnsFromRuns(header, layout string, isSpace func(rune) bool) []Column {
+	starts := []int{}
+	previousSpace := true
+	pos := 0
+	for _, c := range layout {
+		space := isSpace(c)
+		if previousSpace && !space {
+			starts = append(starts, pos)
+		}
+		previousSpace = space
+		pos++
+	}
+
+	columns := make([]Column, len(starts))
+	for i, start := range starts {
+		end := 0
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		columns[i] = Column{
+			Name:  strings.TrimSpace(substring(header, start, end)),
+			Start: start,
+			End:   end,
+		}
+	}
+	return columns
+}
+
+// substring returns the characters of s from start to end.
+// An end of zero extends to the end of s.
+func substring(s string, start, end int) string {
+	if utf8.RuneCountInString(s) == len(s) {
+		// fast path for ASCII
+		if start >= len(s) {
+			return ""
+		}
+		if end == 0 || end > len(s) {
+			return s[start:]
+		}
+		return s[start:end]
+	}
+
+	runes := []rune(s)
+	if start >= len(runes) {
+		return ""
+	}
+	if end == 0 || end > len(runes) {
+		return string(runes[start:])
+	}
+	return string(runes[start:end])
+}
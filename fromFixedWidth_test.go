@@ -0,0 +1,102 @@
+package dataparse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedWidthAll_Columns(t *testing.T) {
+	input := "0000012345JOHN DOE  00150\n" +
+		"\n" +
+		"0000067890JANE ROE  02500\r\n" +
+		"0000011111SHORT\n"
+
+	type account struct {
+		Acct    int    `dataparse:"acct"`
+		Name    string `dataparse:"name"`
+		Balance int    `dataparse:"balance"`
+	}
+
+	accounts := []account{}
+	lines := []int{}
+	offsets := []int64{}
+	for m, err := range FixedWidthAll(strings.NewReader(input), WithColumns(
+		Column{Name: "acct", Start: 0, End: 10},
+		Column{Name: "name", Start: 10, End: 20},
+		Column{Name: "balance", Start: 20},
+	)) {
+		require.Nil(t, err)
+		var a account
+		require.Nil(t, m.To(&a))
+		accounts = append(accounts, a)
+		lines = append(lines, m.Provenance.Line)
+		offsets = append(offsets, m.Provenance.Offset)
+	}
+
+	assert.Equal(t, []account{
+		{Acct: 12345, Name: "JOHN DOE", Balance: 150},
+		{Acct: 67890, Name: "JANE ROE", Balance: 2500},
+		{Acct: 11111, Name: "SHORT", Balance: 0},
+	}, accounts)
+	assert.Equal(t, []int{1, 3, 4}, lines)
+	assert.Equal(t, []int64{0, 27, 54}, offsets)
+}
+
+func TestFixedWidthAll_TrimSpace(t *testing.T) {
+	m, err := single(FixedWidthAll(strings.NewReader("ab  cd  \n"),
+		WithColumns(Column{Name: "a", Start: 0, End: 4}, Column{Name: "b", Start: 4}),
+		WithTrimSpace(false),
+	))
+	require.Nil(t, err)
+	assert.Equal(t, "ab  ", m.MustGet("a").MustString())
+	assert.Equal(t, "cd  ", m.MustGet("b").MustString())
+}
+
+func TestFixedWidthAll_InferHeader(t *testing.T) {
+	input := `id   name      city
+1    Müller    Berlin
+2    Smith     New York
+`
+
+	names := []string{}
+	cities := []string{}
+	for m, err := range FixedWidthAll(strings.NewReader(input)) {
+		require.Nil(t, err)
+		names = append(names, m.MustGet("name").MustString())
+		cities = append(cities, m.MustGet("city").MustString())
+	}
+	assert.Equal(t, []string{"Müller", "Smith"}, names)
+	assert.Equal(t, []string{"Berlin", "New York"}, cities)
+}
+
+func TestFixedWidthAll_InferRuler(t *testing.T) {
+	input := `account no   holder name  balance
+----------   -----------  -------
+0000012345   John Doe         150
+`
+
+	m, err := single(FixedWidthAll(strings.NewReader(input)))
+	require.Nil(t, err)
+	assert.Equal(t, "0000012345", m.MustGet("account no").MustString())
+	assert.Equal(t, "John Doe", m.MustGet("holder name").MustString())
+	assert.Equal(t, 150, m.MustGet("balance").MustInt())
+	assert.Equal(t, 3, m.Provenance.Line)
+}
+
+func TestFixedWidthAll_Errors(t *testing.T) {
+	_, err := single(FixedWidthAll(strings.NewReader("")))
+	assert.ErrorContains(t, err, "missing header line")
+
+	_, err = single(FixedWidthAll(strings.NewReader("abc\n"),
+		WithColumns(Column{Name: "a", Start: 2, End: 1})))
+	assert.ErrorContains(t, err, `invalid column "a"`)
+}
+
+func TestReaderAll_FixedWidth(t *testing.T) {
+	m, err := single(ReaderAll(strings.NewReader("a  b\n1  2\n"), WithFormat("fixedwidth")))
+	require.Nil(t, err)
+	assert.Equal(t, 2, m.MustGet("b").MustInt())
+}
@@ -0,0 +1,375 @@
+package dataparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrTableNotFound is returned when the selected table is not in the
+// document.
+var ErrTableNotFound = errors.New("dataparse: table not found")
+
+// FromHtmlTable returns maps read from the rows of a table in an HTML
+// document.
+//
+// The table is selected by its id with WithTableId, by the text of its
+// <caption> with WithTableCaption or by its index in the document with
+// WithTableIndex. Nested tables are counted in document order.
+//
+// The header rows are the rows in <thead> or, without <thead>, the
+// leading rows consisting only of <th> cells. Without header rows the
+// first row is used. Multiple header rows are joined per column with
+// spaces, e.g. "Contact Email" for a "Contact" cell spanning the
+// "Email" and "Phone" columns. Duplicate headers get a number
+// appended, e.g. "Name" and "Name2". Headers can be overridden with
+// WithHeaders, in which case all rows are returned.
+//
+// Cells spanning multiple rows or columns with rowspan and colspan are
+// repeated in each row and column they span. Values are the text of
+// the cells with whitespace collapsed. Missing cells are returned as
+// empty strings, cells beyond the headers are ignored.
+//
+// FromHtmlTable is a wrapper around HtmlTableAll, returning the results
+// in a channel.
+func FromHtmlTable(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromHtmlTable(cfg))
+}
+
+// FromHtmlTableContext is FromHtmlTable with the passed context, see
+// WithContext.
+func FromHtmlTableContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromHtmlTable(reader, append(opts, WithContext(ctx))...)
+}
+
+// HtmlTableAll returns an iterator over the maps read from the rows of
+// a table in an HTML document, see FromHtmlTable.
+func HtmlTableAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromHtmlTable(cfg)
+}
+
+func fromHtmlTable(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		doc, err := html.Parse(cfg.reader)
+		if err != nil {
+			yield(nil, fmt.Errorf("dataparse: error parsing html: %w", err))
+			return
+		}
+
+		var table *html.Node
+		index := 0
+		for node := range doc.Descendants() {
+			if node.Type != html.ElementNode || node.DataAtom != atom.Table {
+				continue
+			}
+			if cfg.selectTable(index, htmlAttr(node, "id"), htmlCaption(node)) {
+				table = node
+				break
+			}
+			index++
+		}
+		if table == nil {
+			yield(nil, cfg.tableNotFound())
+			return
+		}
+
+		rows := htmlGrid(htmlRows(table))
+		headerRows := 0
+		for headerRows < len(rows)-1 && rows[headerRows].header {
+			headerRows++
+		}
+		if headerRows == 0 {
+			headerRows = 1
+		}
+
+		var headers []string
+		if len(cfg.headers) > 0 {
+			headers = cfg.headers
+			headerRows = 0
+		} else {
+			grid := make([][]string, headerRows)
+			for i := range headerRows {
+				grid[i] = rows[i].cells
+			}
+			headers = tableHeaders(grid)
+		}
+
+		for index, row := range rows[min(headerRows, len(rows)):] {
+			m := tableRecord(headers, row.cells)
+			m.Provenance = Provenance{
+				Source: cfg.source,
+				Member: cfg.member,
+				Index:  index,
+			}
+			if !yield(m, nil) {
+				return
+			}
+		}
+	})
+}
+
+// selectTable returns true if the table with the index, id and caption
+// is selected by the options.
+func (cfg *fromConfig) selectTable(index int, id, caption string) bool {
+	if cfg.tableId != "" || cfg.tableCaption != "" {
+		return (cfg.tableId == "" || cfg.tableId == id) &&
+			(cfg.tableCaption == "" || strings.EqualFold(cfg.tableCaption, caption))
+	}
+	return index == cfg.tableIndex
+}
+
+func (cfg *fromConfig) tableNotFound() error {
+	switch {
+	case cfg.tableId != "":
+		return fmt.Errorf("%w: id %q", ErrTableNotFound, cfg.tableId)
+	case cfg.tableCaption != "":
+		return fmt.Errorf("%w: caption %q", ErrTableNotFound, cfg.tableCaption)
+	default:
+		return fmt.Errorf("%w: index %d", ErrTableNotFound, cfg.tableIndex)
+	}
+}
+
+// tableHeaders returns the keys for the columns of the header rows.
+// The distinct cells of each column are joined with spaces, columns
+// without text are named by their position, e.g. "column3".
+// Duplicate keys are made unique, see uniqueHeaders.
+func tableHeaders(rows [][]string) []string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+
+	headers := make([]string, width)
+	for col := range headers {
+		parts := []string{}
+		for _, row := range rows {
+			if col >= len(row) || row[col] == "" {
+				continue
+			}
+			if len(parts) == 0 || parts[len(parts)-1] != row[col] {
+				parts = append(parts, row[col])
+			}
+		}
+		headers[col] = strings.Join(parts, " ")
+		if headers[col] == "" {
+			headers[col] = "column" + strconv.Itoa(col+1)
+		}
+	}
+	return uniqueHeaders(headers)
+}
+
+// uniqueHeaders appends the lowest number starting at 2 to duplicate
+// headers, e.g. "Name" and "Name2" for a header cell spanning two
+// columns, so no column overwrites another.
+func uniqueHeaders(headers []string) []string {
+	seen := make(map[string]bool, len(headers))
+	for col, header := range headers {
+		name := header
+		for i := 2; seen[name]; i++ {
+			name = header + strconv.Itoa(i)
+		}
+		seen[name] = true
+		headers[col] = name
+	}
+	return headers
+}
+
+// tableRecord returns the map of a table row.
+// Missing cells are empty strings and cells beyond the headers are
+// ignored.
+func tableRecord(headers, cells []string) *Map {
+	m := NewEmptyMap()
+	for i, header := range headers {
+		value := ""
+		if i < len(cells) {
+			value = cells[i]
+		}
+		m.Data[header] = value
+	}
+	return m
+}
+
+type htmlCell struct {
+	text    string
+	header  bool
+	colspan int
+	rowspan int
+}
+
+type htmlRow struct {
+	cells  []htmlCell
+	header bool
+}
+
+// htmlRows returns the rows of table without descending into nested
+// tables. Rows in <thead> are header rows.
+func htmlRows(table *html.Node) []htmlRow {
+	rows := []htmlRow{}
+	var walk func(parent *html.Node, header bool)
+	walk = func(parent *html.Node, header bool) {
+		for node := range parent.ChildNodes() {
+			if node.Type != html.ElementNode {
+				continue
+			}
+			switch node.DataAtom {
+			case atom.Thead:
+				walk(node, true)
+			case atom.Tbody, atom.Tfoot:
+				walk(node, false)
+			case atom.Tr:
+				rows = append(rows, htmlRowOf(node, header))
+			}
+		}
+	}
+	walk(table, false)
+	return rows
+}
+
+func htmlRowOf(tr *html.Node, header bool) htmlRow {
+	row := htmlRow{}
+	allHeaders := true
+	for node := range tr.ChildNodes() {
+		if node.Type != html.ElementNode || (node.DataAtom != atom.Td && node.DataAtom != atom.Th) {
+			continue
+		}
+		cell := htmlCell{
+			text:    htmlText(node),
+			header:  node.DataAtom == atom.Th,
+			colspan: htmlSpan(node, "colspan", 1000),
+			rowspan: htmlSpan(node, "rowspan", 65534),
+		}
+		allHeaders = allHeaders && cell.header
+		row.cells = append(row.cells, cell)
+	}
+	row.header = header || (allHeaders && len(row.cells) > 0)
+	return row
+}
+
+type gridRow struct {
+	cells  []string
+	header bool
+}
+
+// htmlGrid places the cells of rows in a grid, repeating cells in all
+// rows and columns they span.
+func htmlGrid(rows []htmlRow) []gridRow {
+	type span struct {
+		text      string
+		remaining int
+	}
+	spans := []span{}
+
+	grid := make([]gridRow, len(rows))
+	for r, row := range rows {
+		cells := []string{}
+		col := 0
+		fill := func() {
+			for col < len(spans) && spans[col].remaining > 0 {
+				cells = append(cells, spans[col].text)
+				spans[col].remaining--
+				col++
+			}
+		}
+
+		for _, cell := range row.cells {
+			fill()
+			for i := range cell.colspan {
+				if i > 0 {
+					// skip columns spanning from previous rows
+					fill()
+				}
+				cells = append(cells, cell.text)
+				if cell.rowspan > 1 {
+					for len(spans) <= col {
+						spans = append(spans, span{})
+					}
+					spans[col] = span{text: cell.text, remaining: cell.rowspan - 1}
+				}
+				col++
+			}
+		}
+
+		// cells spanning from previous rows after the last cell
+		for ; col < len(spans); col++ {
+			if spans[col].remaining > 0 {
+				cells = append(cells, spans[col].text)
+				spans[col].remaining--
+			} else {
+				cells = append(cells, "")
+			}
+		}
+
+		grid[r] = gridRow{cells: cells, header: row.header}
+	}
+	return grid
+}
+
+// htmlSpan returns the span in the attribute key of node, limited to
+// the range browsers accept.
+func htmlSpan(node *html.Node, key string, limit int) int {
+	span, err := strconv.Atoi(strings.TrimSpace(htmlAttr(node, key)))
+	if err != nil || span < 1 {
+		return 1
+	}
+	return min(span, limit)
+}
+
+func htmlAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Namespace == "" && strings.EqualFold(attr.Key, key) {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// htmlCaption returns the text of the caption of table.
+func htmlCaption(table *html.Node) string {
+	for node := range table.ChildNodes() {
+		if node.Type == html.ElementNode && node.DataAtom == atom.Caption {
+			return htmlText(node)
+		}
+	}
+	return ""
+}
+
+// htmlBlocks are the elements separating words in the text of cells.
+var htmlBlocks = map[atom.Atom]bool{
+	atom.Br: true, atom.P: true, atom.Div: true, atom.Li: true,
+	atom.Table: true, atom.Tr: true, atom.Td: true, atom.Th: true,
+}
+
+// htmlText returns the text content of node with whitespace collapsed.
+// Line breaks and block elements separate words, scripts and styles
+// are ignored.
+func htmlText(node *html.Node) string {
+	b := &strings.Builder{}
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		switch {
+		case node.Type == html.TextNode:
+			b.WriteString(node.Data)
+		case node.Type == html.ElementNode && (node.DataAtom == atom.Script || node.DataAtom == atom.Style):
+			return
+		case node.Type == html.ElementNode && htmlBlocks[node.DataAtom]:
+			b.WriteString(" ")
+			defer b.WriteString(" ")
+		}
+		for child := range node.ChildNodes() {
+			walk(child)
+		}
+	}
+	walk(node)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
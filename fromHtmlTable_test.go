@@ -0,0 +1,121 @@
+package dataparse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const htmlTables = `<!DOCTYPE html>
+<html>
+<body>
+<table>
+	<tr><th>Name</th><th>Status</th></tr>
+	<tr><td>api</td><td>up</td></tr>
+	<tr><td>db <br>primary</td><td><b>degraded</b></td></tr>
+</table>
+
+<table id="services">
+	<caption>Service Status</caption>
+	<thead>
+		<tr><th rowspan="2">Service</th><th colspan="2">Latency</th></tr>
+		<tr><th>p50</th><th>p99</th></tr>
+	</thead>
+	<tbody>
+		<tr><td>api</td><td>10</td><td>50</td></tr>
+		<tr><td rowspan="2">db</td><td colspan="2">n/a</td></tr>
+		<tr><td>20</td></tr>
+	</tbody>
+</table>
+
+<table>
+	<tr><td>first</td><td>second</td></tr>
+	<tr><td>a</td><td>b<table><tr><td>nested</td></tr></table></td></tr>
+</table>
+</body>
+</html>`
+
+func readHtmlTable(t *testing.T, input string, opts ...FromOption) []map[any]any {
+	t.Helper()
+	results := []map[any]any{}
+	for m, err := range HtmlTableAll(strings.NewReader(input), opts...) {
+		require.Nil(t, err)
+		results = append(results, m.Data)
+	}
+	return results
+}
+
+func TestHtmlTableAll(t *testing.T) {
+	assert.Equal(t, []map[any]any{
+		{"Name": "api", "Status": "up"},
+		{"Name": "db primary", "Status": "degraded"},
+	}, readHtmlTable(t, htmlTables))
+}
+
+func TestHtmlTableAll_Spans(t *testing.T) {
+	expected := []map[any]any{
+		{"Service": "api", "Latency p50": "10", "Latency p99": "50"},
+		{"Service": "db", "Latency p50": "n/a", "Latency p99": "n/a"},
+		{"Service": "db", "Latency p50": "20", "Latency p99": ""},
+	}
+
+	assert.Equal(t, expected, readHtmlTable(t, htmlTables, WithTableIndex(1)))
+	assert.Equal(t, expected, readHtmlTable(t, htmlTables, WithTableId("services")))
+	assert.Equal(t, expected, readHtmlTable(t, htmlTables, WithTableCaption("service status")))
+
+	// a colspan across a column spanning from the previous row
+	assert.Equal(t, []map[any]any{
+		{"a": "1", "b": "2", "c": "3"},
+		{"a": "4", "b": "2", "c": "4"},
+	}, readHtmlTable(t, `<table>
+<tr><th>a</th><th>b</th><th>c</th></tr>
+<tr><td>1</td><td rowspan=2>2</td><td>3</td></tr>
+<tr><td colspan=2>4</td></tr>
+</table>`))
+}
+
+func TestHtmlTableAll_DuplicateHeaders(t *testing.T) {
+	assert.Equal(t, []map[any]any{
+		{"Name": "Ada", "Name2": "Lovelace", "Name3": "x", "column4": "y"},
+	}, readHtmlTable(t, `<table>
+<tr><th colspan="2">Name</th><th>Name</th><th></th></tr>
+<tr><td>Ada</td><td>Lovelace</td><td>x</td><td>y</td></tr>
+</table>`))
+}
+
+func TestHtmlTableAll_NestedAndNoHeader(t *testing.T) {
+	// the first row is used as header without th cells, the nested
+	// table is counted after its parent
+	assert.Equal(t, []map[any]any{
+		{"first": "a", "second": "b nested"},
+	}, readHtmlTable(t, htmlTables, WithTableIndex(2)))
+
+	assert.Equal(t, []map[any]any{
+		{"column1": "nested"},
+	}, readHtmlTable(t, htmlTables, WithTableIndex(3), WithHeaders("column1")))
+}
+
+func TestHtmlTableAll_WithHeaders(t *testing.T) {
+	results := readHtmlTable(t, htmlTables, WithHeaders("service", "state"))
+	require.Len(t, results, 3)
+	assert.Equal(t, map[any]any{"service": "Name", "state": "Status"}, results[0])
+}
+
+func TestHtmlTableAll_NotFound(t *testing.T) {
+	for _, opt := range []FromOption{WithTableIndex(5), WithTableId("missing"), WithTableCaption("missing")} {
+		for _, err := range HtmlTableAll(strings.NewReader(htmlTables), opt) {
+			require.ErrorIs(t, err, ErrTableNotFound)
+		}
+	}
+}
+
+func TestHtmlTableAll_Sniff(t *testing.T) {
+	_, ok := sniffHtml([]byte("\n  <!doctype html><html>"))
+	assert.True(t, ok)
+	_, ok = sniffHtml([]byte("<table><tr>"))
+	assert.True(t, ok)
+	_, ok = sniffHtml([]byte("<?xml version=\"1.0\"?><root/>"))
+	assert.False(t, ok)
+}
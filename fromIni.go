@@ -0,0 +1,159 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// FromIni returns a single map parsed from an INI file.
+//
+// Keys in sections are nested in a map under the section name, so
+// "key" in "[section]" can be retrieved with Get("section.key").
+// Section names containing dots are nested as well, e.g.
+// "[server.http]" becomes "server" -> "http".
+// Keys before the first section are stored at the top level.
+// Dotted keys are nested like in FromProperties, including sections
+// that are also a key, e.g. "x" in "x=1" and "[x]".
+//
+// Lines starting with ";" or "#" are comments. Values are trimmed and
+// surrounding quotes are removed. Values are returned as strings,
+// which Value converts as needed.
+//
+// Malformed lines are handled according to the error policy.
+//
+// FromIni is a wrapper around IniAll, returning the results in
+// a channel.
+func FromIni(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromIni(cfg))
+}
+
+// FromIniContext is FromIni with the passed context, see WithContext.
+func FromIniContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromIni(reader, append(opts, WithContext(ctx))...)
+}
+
+// IniAll returns an iterator over the map parsed from an INI file, see
+// FromIni.
+func IniAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromIni(cfg)
+}
+
+func fromIni(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		m := NewEmptyMap()
+		m.Provenance = Provenance{Source: cfg.source, Member: cfg.member}
+
+		// section is the path of the section keys are currently
+		// added to
+		var section []string
+		root := map[string]any{}
+
+		scanner := bufio.NewScanner(cfg.reader)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || text[0] == ';' || text[0] == '#' {
+				continue
+			}
+
+			var err error
+			if text[0] == '[' {
+				if !strings.HasSuffix(text, "]") {
+					err = fmt.Errorf("dataparse: unterminated section %q", text)
+				} else {
+					name := strings.TrimSpace(text[1 : len(text)-1])
+					section = strings.Split(name, ".")
+					nestedSection(root, section)
+				}
+			} else if key, value, ok := strings.Cut(text, "="); ok {
+				path := slices.Concat(section, strings.Split(strings.TrimSpace(key), "."))
+				setNested(root, path, unquote(strings.TrimSpace(value)))
+			} else {
+				err = fmt.Errorf("dataparse: expected key=value or [section], got %q", text)
+			}
+
+			if err != nil {
+				p := m.Provenance
+				p.Line = line
+				if !cfg.recordError(yield, NewErrRecord(p, err)) {
+					return
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, NewErrRecord(m.Provenance, err))
+			return
+		}
+
+		for key, value := range root {
+			m.Data[key] = value
+		}
+		yield(m, nil)
+	})
+}
+
+// nestedSection creates the maps of the section at the path in root,
+// so empty sections are empty maps. The keys of a section that is
+// also a value are stored as dotted keys, see setNested.
+func nestedSection(root map[string]any, path []string) {
+	current := root
+	for _, key := range path {
+		next, ok := current[key]
+		if !ok {
+			next = map[string]any{}
+			current[key] = next
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			return
+		}
+		current = nested
+	}
+}
+
+// setNested sets the value at the path in root, creating missing maps.
+//
+// A key can hold a value and be the prefix of other keys, e.g. "host"
+// and "host.port". The value is stored under the key and the other
+// keys are stored as dotted keys next to it, which Map.Get resolves
+// as well.
+func setNested(root map[string]any, path []string, value any) {
+	current := root
+	for i, key := range path[:len(path)-1] {
+		next, ok := current[key]
+		if !ok {
+			next = map[string]any{}
+			current[key] = next
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			current[strings.Join(path[i:], ".")] = value
+			return
+		}
+		current = nested
+	}
+
+	key := path[len(path)-1]
+	if nested, ok := current[key].(map[string]any); ok {
+		flattenMap(current, key+".", nested)
+	}
+	current[key] = value
+}
+
+// unquote removes matching single or double quotes around value.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,104 @@
+package dataparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const iniInput = `
+; global settings
+name = legacy
+
+[database]
+host = db.example.com
+port = 5432
+password = "secret value"
+
+# nested section
+[server.http]
+listen = :8080
+`
+
+func TestIniAll(t *testing.T) {
+	m, err := single(IniAll(strings.NewReader(iniInput)))
+	require.Nil(t, err)
+
+	assert.Equal(t, "legacy", m.MustGet("name").MustString())
+	assert.Equal(t, "db.example.com", m.MustGet("database.host").MustString())
+	assert.Equal(t, 5432, m.MustGet("database.port").MustInt())
+	assert.Equal(t, "secret value", m.MustGet("database.password").MustString())
+	assert.Equal(t, ":8080", m.MustGet("server.http.listen").MustString())
+}
+
+func TestFrom_Ini(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"config.ini", "config.cfg"} {
+		path := filepath.Join(dir, name)
+		require.Nil(t, os.WriteFile(path, []byte(iniInput), 0o644))
+
+		m, err := FromSingle(path)
+		require.Nil(t, err)
+		assert.Equal(t, "db.example.com", m.MustGet("database.host").MustString())
+		assert.Equal(t, path, m.Provenance.Source)
+	}
+}
+
+func TestIniAll_Malformed(t *testing.T) {
+	input := `
+[section]
+key = value
+malformed
+[unterminated
+other = value
+`
+
+	_, err := single(IniAll(strings.NewReader(input)))
+	assert.ErrorContains(t, err, "record 0 line 4")
+
+	results := []*Map{}
+	errs := []error{}
+	for m, err := range IniAll(strings.NewReader(input), WithErrorPolicy(ErrorPolicySkip)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, m)
+	}
+	assert.Len(t, errs, 2)
+	require.Len(t, results, 1)
+	assert.Equal(t, "value", results[0].MustGet("section.other").MustString())
+}
+
+func TestIniAll_ValueAndPrefix(t *testing.T) {
+	input := `
+[server]
+host = example.com
+host.port = 8080
+`
+	m, err := single(IniAll(strings.NewReader(input)))
+	require.Nil(t, err)
+	assert.Equal(t, "example.com", m.MustGet("server.host").MustString())
+	assert.Equal(t, 8080, m.MustGet("server.host.port").MustInt())
+
+	// sections that are also a value
+	input = `
+x = 1
+[x]
+y = 2
+[x.z]
+`
+	m, err = single(IniAll(strings.NewReader(input)))
+	require.Nil(t, err)
+	assert.Equal(t, map[any]any{"x": "1", "x.y": "2"}, m.Data)
+	assert.Equal(t, 2, m.MustGet("x.y").MustInt())
+
+	// the same shape as properties
+	p, err := single(PropertiesAll(strings.NewReader("x = 1\nx.y = 2\n")))
+	require.Nil(t, err)
+	assert.Equal(t, p.Data, m.Data)
+}
@@ -0,0 +1,430 @@
+package dataparse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// FromJson returns maps parsed from a stream which may consist of:
+// 1. A single JSON document
+// 2. A stream of JSON documents
+// 3. An array of JSON documents
+//
+// Arrays are decoded one element at a time, so memory usage is
+// proportional to the size of a single element instead of the size of
+// the array.
+//
+// Arrays nested in a wrapper document can be streamed by passing the
+// path to the array with WithJsonPath.
+//
+// FromJson is a wrapper around JsonAll, returning the results in
+// a channel.
+func FromJson(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromJson(cfg))
+}
+
+// FromJsonContext is FromJson with the passed context, see WithContext.
+func FromJsonContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromJson(reader, append(opts, WithContext(ctx))...)
+}
+
+// JsonAll returns an iterator over the maps parsed from a JSON stream,
+// see FromJson.
+func JsonAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromJson(cfg)
+}
+
+// FromJsonSingle is a wrapper around JsonAll and returns the first map
+// and error in the result set.
+// It is only intended for instances where it is already known that the
+// input can only contain a single document.
+//
+// Reading is stopped after the first result.
+func FromJsonSingle(reader io.Reader, opts ...FromOption) (*Map, error) {
+	return single(JsonAll(reader, opts...))
+}
+
+func fromJson(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		r := &jsonReader{
+			cfg:      cfg,
+			buffered: bufio.NewReader(cfg.reader),
+			yield:    yield,
+		}
+		r.source = &prefixReader{reader: r.buffered}
+		r.decoder = json.NewDecoder(r.source)
+
+		for {
+			next, err := r.peek()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, err)
+				}
+				return
+			}
+
+			if len(cfg.jsonPath) > 0 {
+				if !r.path(cfg.jsonPath) {
+					return
+				}
+				continue
+			}
+
+			if !r.value(next) {
+				return
+			}
+		}
+	})
+}
+
+// jsonReader reads maps from a JSON stream and keeps track of the
+// provenance of the records.
+//
+// All methods yielding maps return false if the iteration should stop.
+type jsonReader struct {
+	cfg      *fromConfig
+	buffered *bufio.Reader
+	source   *prefixReader
+	decoder  *json.Decoder
+	yield    func(*Map, error) bool
+
+	// skipped is the number of bytes consumed from the input without
+	// passing through the decoder.
+	skipped int64
+	index   int
+}
+
+// prefixReader reads from prefix before reading from reader.
+// It is used to pass bytes the previous decoder already read from the
+// input to a new decoder after resynchronizing.
+type prefixReader struct {
+	prefix []byte
+	reader io.Reader
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	if len(p.prefix) > 0 {
+		n := copy(b, p.prefix)
+		p.prefix = p.prefix[n:]
+		return n, nil
+	}
+	return p.reader.Read(b)
+}
+
+// peek returns the first byte of the next value in the stream
+// without consuming it.
+// The decoder reads ahead, so the next value is either in the buffer
+// of the decoder, in the prefix of the source or in the underlying
+// reader.
+func (r *jsonReader) peek() (byte, error) {
+	rest := r.decoder.Buffered()
+	c := make([]byte, 1)
+	for {
+		if n, _ := rest.Read(c); n == 0 {
+			break
+		}
+		if !isJsonSpace(c[0]) {
+			return c[0], nil
+		}
+	}
+
+	for len(r.source.prefix) > 0 {
+		if !isJsonSpace(r.source.prefix[0]) {
+			return r.source.prefix[0], nil
+		}
+		r.source.prefix = r.source.prefix[1:]
+		r.skipped++
+	}
+
+	for {
+		peeked, err := r.buffered.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !isJsonSpace(peeked[0]) {
+			return peeked[0], nil
+		}
+		if _, err := r.buffered.ReadByte(); err != nil {
+			return 0, err
+		}
+		r.skipped++
+	}
+}
+
+func isJsonSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// offset returns the offset of the next value in the input.
+// Whitespace and separating commas before the value are skipped.
+func (r *jsonReader) offset() int64 {
+	offset := r.skipped + r.decoder.InputOffset()
+	rest := r.decoder.Buffered()
+	c := make([]byte, 1)
+	for {
+		if n, _ := rest.Read(c); n == 0 || !(isJsonSpace(c[0]) || c[0] == ',') {
+			return offset
+		}
+		offset++
+	}
+}
+
+// provenance returns the provenance of the record at offset and
+// advances the record index.
+func (r *jsonReader) provenance(offset int64) Provenance {
+	p := Provenance{
+		Source: r.cfg.source,
+		Member: r.cfg.member,
+		Index:  r.index,
+		Offset: offset,
+	}
+	r.index++
+	return p
+}
+
+// record yields elem as a map or an error if the record failed to
+// decode.
+func (r *jsonReader) record(offset int64, elem any, err error) bool {
+	p := r.provenance(offset)
+	if err != nil {
+		r.yield(nil, NewErrRecord(p, err))
+		return false
+	}
+
+	m, err := NewMap(elem)
+	if err != nil {
+		return r.cfg.recordError(r.yield,
+			NewErrRecord(p, fmt.Errorf("dataparse: unhandled type %T: %w", elem, err)))
+	}
+	m.Provenance = p
+	return r.yield(m, nil)
+}
+
+// decodeError yields the error of a top-level value that failed to
+// decode.
+// After syntax errors the decoder is resynchronized on the next line if
+// the error policy allows to continue.
+func (r *jsonReader) decodeError(offset int64, err error) bool {
+	recordErr := NewErrRecord(r.provenance(offset), err)
+
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return r.fail(recordErr)
+	}
+
+	if !r.cfg.recordError(r.yield, recordErr) {
+		return false
+	}
+
+	if err := r.resync(); err != nil {
+		return r.fail(err)
+	}
+	return true
+}
+
+// resync discards the malformed value the decoder failed on up to the
+// end of its line and creates a new decoder for the remaining input.
+func (r *jsonReader) resync() error {
+	consumed := r.skipped + r.decoder.InputOffset()
+
+	// The buffer of the decoder starts at the malformed value.
+	rest, err := io.ReadAll(r.decoder.Buffered())
+	if err != nil {
+		return err
+	}
+	rest = append(rest, r.source.prefix...)
+
+	start := 0
+	for start < len(rest) && isJsonSpace(rest[start]) {
+		start++
+	}
+
+	if i := bytes.IndexByte(rest[start:], '\n'); i >= 0 {
+		consumed += int64(start + i + 1)
+		rest = rest[start+i+1:]
+	} else {
+		consumed += int64(len(rest))
+		rest = nil
+		line, err := r.buffered.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		consumed += int64(len(line))
+	}
+
+	r.skipped = consumed
+	r.source = &prefixReader{prefix: rest, reader: r.buffered}
+	r.decoder = json.NewDecoder(r.source)
+	return nil
+}
+
+// fail yields err and stops the iteration.
+func (r *jsonReader) fail(err error) bool {
+	r.yield(nil, err)
+	return false
+}
+
+// value yields the next value of the decoder.
+// Arrays are streamed element by element, all other values are yielded
+// as a single map.
+func (r *jsonReader) value(next byte) bool {
+	if next != '[' {
+		offset := r.offset()
+		// decoder refuses to decode into Map or map[any]any
+		var m any
+		if err := r.decoder.Decode(&m); err != nil {
+			return r.decodeError(offset, err)
+		}
+		return r.record(offset, m, nil)
+	}
+
+	if _, err := r.decoder.Token(); err != nil {
+		return r.fail(err)
+	}
+	return r.array()
+}
+
+// array yields the elements of an array whose opening bracket was
+// already consumed, one element at a time.
+func (r *jsonReader) array() bool {
+	for r.decoder.More() {
+		offset := r.offset()
+		var elem any
+		err := r.decoder.Decode(&elem)
+		if !r.record(offset, elem, err) {
+			return false
+		}
+	}
+
+	// consume closing bracket
+	if _, err := r.decoder.Token(); err != nil {
+		return r.fail(err)
+	}
+	return true
+}
+
+// path descends into the next document following path, yields the
+// value at the path and consumes the remainder of the document.
+func (r *jsonReader) path(path []string) bool {
+	for i, key := range path {
+		if err := r.expectDelim('{'); err != nil {
+			return r.fail(fmt.Errorf("dataparse: error descending into %q: %w",
+				strings.Join(path[:i], "."), err))
+		}
+
+		found := false
+		for r.decoder.More() {
+			token, err := r.decoder.Token()
+			if err != nil {
+				return r.fail(err)
+			}
+			if token == key {
+				found = true
+				break
+			}
+			if err := r.skip(); err != nil {
+				return r.fail(err)
+			}
+		}
+
+		if !found {
+			return r.fail(fmt.Errorf("dataparse: path %q not found in document",
+				strings.Join(path[:i+1], ".")))
+		}
+	}
+
+	offset := r.offset()
+	token, err := r.decoder.Token()
+	if err != nil {
+		return r.fail(err)
+	}
+	switch token {
+	case json.Delim('['):
+		if !r.array() {
+			return false
+		}
+	case json.Delim('{'):
+		m := map[string]any{}
+		for r.decoder.More() {
+			key, err := r.decoder.Token()
+			if err != nil {
+				return r.record(offset, nil, err)
+			}
+			var value any
+			if err := r.decoder.Decode(&value); err != nil {
+				return r.record(offset, nil, err)
+			}
+			m[fmt.Sprint(key)] = value
+		}
+		if _, err := r.decoder.Token(); err != nil {
+			return r.record(offset, nil, err)
+		}
+		if !r.record(offset, m, nil) {
+			return false
+		}
+	default:
+		return r.fail(fmt.Errorf("dataparse: unhandled type %T at path %q",
+			token, strings.Join(path, ".")))
+	}
+
+	// consume the rest of the enclosing objects
+	for range path {
+		for r.decoder.More() {
+			if _, err := r.decoder.Token(); err != nil {
+				return r.fail(err)
+			}
+			if err := r.skip(); err != nil {
+				return r.fail(err)
+			}
+		}
+		if _, err := r.decoder.Token(); err != nil {
+			return r.fail(err)
+		}
+	}
+
+	return true
+}
+
+// expectDelim reads the next token and returns an error if it is not
+// the expected delimiter.
+func (r *jsonReader) expectDelim(delim json.Delim) error {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("dataparse: expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+// skip consumes the next value without decoding it.
+func (r *jsonReader) skip() error {
+	depth := 0
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('['), json.Delim('{'):
+			depth++
+		case json.Delim(']'), json.Delim('}'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package dataparse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endlessJsonArray is a reader producing a JSON array that never ends.
+type endlessJsonArray struct {
+	i   int
+	buf []byte
+}
+
+func (r *endlessJsonArray) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.i == 0 {
+			r.buf = append(r.buf, '[')
+		} else {
+			r.buf = append(r.buf, ',')
+		}
+		r.buf = fmt.Appendf(r.buf, `{"id": %d}`, r.i)
+		r.i++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestJsonAll_StreamArray(t *testing.T) {
+	reader := &endlessJsonArray{}
+
+	count := 0
+	for m, err := range JsonAll(reader) {
+		require.Nil(t, err)
+		assert.Equal(t, count, m.MustGet("id").MustInt())
+		count++
+		if count == 1000 {
+			break
+		}
+	}
+	assert.Equal(t, 1000, count)
+}
+
+func TestJsonAll_Mixed(t *testing.T) {
+	input := `
+	[{"id": 1}, {"id": 2}]
+	{"id": 3}
+	[]
+	[{"id": 4}]
+	`
+
+	ids := []int{}
+	for m, err := range JsonAll(strings.NewReader(input)) {
+		require.Nil(t, err)
+		ids = append(ids, m.MustGet("id").MustInt())
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, ids)
+}
+
+func TestJsonAll_InvalidElement(t *testing.T) {
+	var lastErr error
+	for _, err := range JsonAll(strings.NewReader(`[{"id": 1}, 5]`)) {
+		lastErr = err
+	}
+	require.NotNil(t, lastErr)
+}
+
+func TestJsonAll_Path(t *testing.T) {
+	input := `
+	{"meta": {"count": 2, "nested": [[1], {"a": []}]}, "data": {"items": [{"id": 1}, {"id": 2}], "after": true}, "trailer": "x"}
+	{"data": {"items": [{"id": 3}]}}
+	{"data": {"items": {"id": 4}}}
+	`
+
+	ids := []int{}
+	for m, err := range JsonAll(strings.NewReader(input), WithJsonPath("data.items")) {
+		require.Nil(t, err)
+		ids = append(ids, m.MustGet("id").MustInt())
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, ids)
+}
+
+func TestJsonAll_PathMissing(t *testing.T) {
+	var lastErr error
+	for _, err := range JsonAll(strings.NewReader(`{"data": {"other": []}}`), WithJsonPath("data.items")) {
+		lastErr = err
+	}
+	require.ErrorContains(t, lastErr, `"data.items" not found`)
+
+	lastErr = nil
+	for _, err := range JsonAll(strings.NewReader(`{"data": [1]}`), WithJsonPath("data.items")) {
+		lastErr = err
+	}
+	require.NotNil(t, lastErr)
+}
+
+func TestFromJsonSingle_Whitespace(t *testing.T) {
+	_, err := FromJsonSingle(strings.NewReader("   \n\t"))
+	require.ErrorIs(t, err, ErrNoResult)
+}
@@ -0,0 +1,103 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+)
+
+// FromLines returns a map for each line of unstructured text like log
+// files, matched against the pattern defined with WithPattern.
+//
+// The keys of the maps are the names of the capture groups in the
+// pattern. Unnamed groups are ignored and groups not participating in
+// the match are nil.
+//
+// Empty lines are skipped. Lines not matching the pattern are reported
+// as record errors and handled according to the error policy.
+//
+// FromLines is a wrapper around LinesAll, returning the results in
+// a channel.
+func FromLines(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromLines(cfg))
+}
+
+// FromLinesContext is FromLines with the passed context, see
+// WithContext.
+func FromLinesContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromLines(reader, append(opts, WithContext(ctx))...)
+}
+
+// LinesAll returns an iterator over the maps parsed from the lines of
+// unstructured text, see FromLines.
+func LinesAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromLines(cfg)
+}
+
+func fromLines(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		if cfg.pattern == nil {
+			yield(nil, errors.New("dataparse: no pattern defined, see WithPattern"))
+			return
+		}
+
+		names := cfg.pattern.SubexpNames()
+		r := &lineReader{reader: bufio.NewReader(cfg.reader)}
+		for index := 0; ; {
+			line, err := r.next()
+			p := Provenance{
+				Source: cfg.source,
+				Member: cfg.member,
+				Index:  index,
+				Line:   r.line,
+				Offset: r.offset,
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, NewErrRecord(p, err))
+				}
+				return
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			index++
+
+			match := cfg.pattern.FindStringSubmatchIndex(line)
+			if match == nil {
+				if !cfg.recordError(yield, NewErrRecord(p, fmt.Errorf("%w: %q", ErrNoMatch, line))) {
+					return
+				}
+				continue
+			}
+
+			m := NewEmptyMap()
+			for i, name := range names {
+				if name == "" {
+					continue
+				}
+				// groups with the same name are allowed, the first
+				// participating group wins
+				if m.Data[name] != nil {
+					continue
+				}
+				m.Data[name] = nil
+				if start := match[2*i]; start >= 0 {
+					m.Data[name] = line[start:match[2*i+1]]
+				}
+			}
+			m.Provenance = p
+			if !yield(m, nil) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package dataparse
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinesAll(t *testing.T) {
+	input := `2026-01-02T03:04:05Z INFO started
+2026-01-02T03:04:06Z WARN disk almost full
+
+not a log line
+2026-01-02T03:04:07Z ERROR
+`
+
+	pattern := MustGrok(`^%{TIMESTAMP_ISO8601:ts} %{LOGLEVEL:level}(?: %{GREEDYDATA:msg})?$`)
+
+	levels := []string{}
+	errs := []error{}
+	var last *Map
+	for m, err := range LinesAll(strings.NewReader(input), WithPattern(pattern), WithErrorPolicy(ErrorPolicySkip)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		levels = append(levels, m.MustGet("level").MustString())
+		last = m
+	}
+
+	assert.Equal(t, []string{"INFO", "WARN", "ERROR"}, levels)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrNoMatch)
+	assert.ErrorContains(t, errs[0], "record 2 line 4")
+
+	require.NotNil(t, last)
+	assert.True(t, last.MustGet("msg").IsNil())
+	assert.Equal(t, 5, last.Provenance.Line)
+	assert.Equal(t, 3, last.Provenance.Index)
+}
+
+func TestLinesAll_NoPattern(t *testing.T) {
+	_, err := single(LinesAll(strings.NewReader("line\n")))
+	assert.ErrorContains(t, err, "no pattern defined")
+}
+
+func TestLinesAll_Regexp(t *testing.T) {
+	m, err := single(LinesAll(strings.NewReader("a=1 b=2"),
+		WithPattern(regexp.MustCompile(`a=(?P<a>\d+) (b)=(?P<b>\d+)`))))
+	require.Nil(t, err)
+	assert.Equal(t, map[any]any{"a": "1", "b": "2"}, m.Data)
+}
+
+func TestLinesAll_Presets(t *testing.T) {
+	cases := map[string]struct {
+		pattern  *regexp.Regexp
+		line     string
+		expected map[string]any
+	}{
+		"common": {
+			pattern: PatternCommonLog,
+			line:    `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+			expected: map[string]any{
+				"clientip": "127.0.0.1",
+				"auth":     "frank",
+				"verb":     "GET",
+				"request":  "/apache_pb.gif",
+				"response": "200",
+				"bytes":    "2326",
+			},
+		},
+		"combined": {
+			pattern: PatternCombinedLog,
+			line:    `203.0.113.9 - - [02/Jan/2026:03:04:05 +0000] "POST /api/v1/items?id=3 HTTP/1.1" 201 - "https://example.com/" "curl/8.5.0"`,
+			expected: map[string]any{
+				"clientip":    "203.0.113.9",
+				"verb":        "POST",
+				"request":     "/api/v1/items?id=3",
+				"httpversion": "1.1",
+				"response":    "201",
+				"bytes":       nil,
+				"referrer":    "https://example.com/",
+				"agent":       "curl/8.5.0",
+			},
+		},
+		"syslog3164": {
+			pattern: PatternSyslog3164,
+			line:    `<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8`,
+			expected: map[string]any{
+				"priority":  "34",
+				"timestamp": "Oct 11 22:14:15",
+				"hostname":  "mymachine",
+				"program":   "su",
+				"pid":       "123",
+				"message":   "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		"syslog5424": {
+			pattern: PatternSyslog5424,
+			line:    `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application"] An application event`,
+			expected: map[string]any{
+				"priority":        "165",
+				"version":         "1",
+				"timestamp":       "2003-10-11T22:14:15.003Z",
+				"hostname":        "mymachine.example.com",
+				"app_name":        "evntslog",
+				"proc_id":         nil,
+				"msg_id":          "ID47",
+				"structured_data": `[exampleSDID@32473 iut="3" eventSource="Application"]`,
+				"message":         "An application event",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m, err := single(LinesAll(strings.NewReader(tc.line), WithPattern(tc.pattern)))
+			require.Nil(t, err)
+			for key, value := range tc.expected {
+				assert.Equal(t, value, m.Data[key], key)
+			}
+		})
+	}
+}
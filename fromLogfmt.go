@@ -0,0 +1,93 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+)
+
+// FromLogfmt returns a map for each line of logfmt formatted text like
+//
+//	level=info msg="user logged in" user=alice admin
+//
+// Pairs are separated by whitespace. Values can be quoted to contain
+// whitespace, quotes inside are escaped with a backslash. Bare keys
+// without a value are nil, keys with an empty value like "key=" are
+// empty strings.
+//
+// Empty lines are skipped. Malformed lines, e.g. with unterminated
+// quotes, are reported as record errors and handled according to the
+// error policy.
+//
+// FromLogfmt is a wrapper around LogfmtAll, returning the results in
+// a channel.
+func FromLogfmt(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromLogfmt(cfg))
+}
+
+// FromLogfmtContext is FromLogfmt with the passed context, see
+// WithContext.
+func FromLogfmtContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromLogfmt(reader, append(opts, WithContext(ctx))...)
+}
+
+// LogfmtAll returns an iterator over the maps parsed from logfmt
+// formatted text, see FromLogfmt.
+func LogfmtAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromLogfmt(cfg)
+}
+
+func fromLogfmt(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		r := &lineReader{reader: bufio.NewReader(cfg.reader)}
+		for index := 0; ; {
+			line, err := r.next()
+			p := Provenance{
+				Source: cfg.source,
+				Member: cfg.member,
+				Index:  index,
+				Line:   r.line,
+				Offset: r.offset,
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, NewErrRecord(p, err))
+				}
+				return
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			index++
+
+			if unterminatedQuote(line) {
+				if !cfg.recordError(yield, NewErrRecord(p, errors.New("dataparse: unterminated quote"))) {
+					return
+				}
+				continue
+			}
+
+			m := NewEmptyMap()
+			m.Data = parseKV(line, logfmtSeparator, "=", true)
+			m.Provenance = p
+			if !yield(m, nil) {
+				return
+			}
+		}
+	})
+}
+
+// logfmtSeparator matches whitespace between logfmt pairs.
+func logfmtSeparator(rest string) int {
+	if rest[0] == ' ' || rest[0] == '\t' {
+		return 1
+	}
+	return 0
+}
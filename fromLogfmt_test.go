@@ -0,0 +1,48 @@
+package dataparse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogfmtAll(t *testing.T) {
+	input := `level=info msg="user logged in" user=alice admin
+level=warn  msg="quote \"inside\"" empty= path=/a=b
+
+level=error msg="unterminated
+level=debug msg=done
+`
+
+	results := []*Map{}
+	errs := []error{}
+	for m, err := range LogfmtAll(strings.NewReader(input), WithErrorPolicy(ErrorPolicySkip)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, m)
+	}
+
+	require.Len(t, results, 3)
+	assert.Equal(t, map[any]any{
+		"level": "info",
+		"msg":   "user logged in",
+		"user":  "alice",
+		"admin": nil,
+	}, results[0].Data)
+	assert.Equal(t, map[any]any{
+		"level": "warn",
+		"msg":   `quote "inside"`,
+		"empty": "",
+		"path":  "/a=b",
+	}, results[1].Data)
+	assert.Equal(t, "done", results[2].MustGet("msg").MustString())
+	assert.Equal(t, 5, results[2].Provenance.Line)
+
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "record 2 line 4")
+	assert.ErrorContains(t, errs[0], "unterminated quote")
+}
@@ -0,0 +1,191 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"iter"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// FromMarkdownTable returns maps read from the rows of a pipe table in
+// a Markdown document as in GitHub Flavored Markdown.
+//
+// The table is selected by the text of the nearest heading before it
+// with WithTableCaption, by the anchor GitHub generates for that
+// heading with WithTableId, e.g. "service-status" for
+// "## Service Status", or by its index in the document with
+// WithTableIndex. Tables in fenced code blocks are ignored.
+//
+// The header row of the table defines the keys, which can be
+// overridden with WithHeaders. Values are the trimmed text of the
+// cells with escaped pipes unescaped, inline formatting is kept.
+// Missing cells are returned as empty strings, cells beyond the headers
+// are ignored.
+//
+// FromMarkdownTable is a wrapper around MarkdownTableAll, returning the
+// results in a channel.
+func FromMarkdownTable(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromMarkdownTable(cfg))
+}
+
+// FromMarkdownTableContext is FromMarkdownTable with the passed
+// context, see WithContext.
+func FromMarkdownTableContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromMarkdownTable(reader, append(opts, WithContext(ctx))...)
+}
+
+// MarkdownTableAll returns an iterator over the maps read from the rows
+// of a pipe table in a Markdown document, see FromMarkdownTable.
+func MarkdownTableAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromMarkdownTable(cfg)
+}
+
+// markdownDelimiterRow matches the row separating the header from the
+// body of a table, e.g. "| --- | :-: |".
+var markdownDelimiterRow = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+func fromMarkdownTable(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		scanner := bufio.NewScanner(cfg.reader)
+		scanner.Buffer(nil, 1024*1024)
+
+		var (
+			line     int
+			previous string
+			heading  string
+			fence    string
+			tables   int
+			headers  []string
+			index    int
+		)
+		for scanner.Scan() {
+			line++
+			text := scanner.Text()
+
+			if headers != nil {
+				// the table ends at the first line that is not a row
+				if strings.TrimSpace(text) == "" || !strings.Contains(text, "|") {
+					return
+				}
+				m := tableRecord(headers, markdownCells(text))
+				m.Provenance = Provenance{
+					Source: cfg.source,
+					Member: cfg.member,
+					Index:  index,
+					Line:   line,
+				}
+				index++
+				if !yield(m, nil) {
+					return
+				}
+				continue
+			}
+
+			trimmed := strings.TrimSpace(text)
+			if marker, ok := markdownFence(trimmed); ok {
+				switch {
+				case fence == "":
+					fence = marker
+				case strings.HasPrefix(marker, fence):
+					fence = ""
+				}
+				previous = ""
+				continue
+			}
+			if fence != "" {
+				continue
+			}
+
+			if strings.HasPrefix(trimmed, "#") {
+				heading = strings.TrimSpace(strings.TrimRight(strings.TrimLeft(trimmed, "#"), "#"))
+				previous = ""
+				continue
+			}
+
+			if strings.Contains(previous, "|") && markdownDelimiterRow.MatchString(text) {
+				header := markdownCells(previous)
+				if len(header) == len(markdownCells(text)) {
+					if cfg.selectTable(tables, markdownAnchor(heading), heading) {
+						headers = uniqueHeaders(header)
+						if len(cfg.headers) > 0 {
+							headers = cfg.headers
+						}
+						previous = ""
+						continue
+					}
+					tables++
+				}
+			}
+			previous = text
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(nil, err)
+			return
+		}
+		if headers == nil {
+			yield(nil, cfg.tableNotFound())
+		}
+	})
+}
+
+// markdownCells splits a table row into its trimmed cells.
+// Leading and trailing pipes are optional and escaped pipes are part of
+// the cells.
+func markdownCells(row string) []string {
+	row = strings.TrimSpace(row)
+	row = strings.TrimPrefix(row, "|")
+	if strings.HasSuffix(row, "|") && !strings.HasSuffix(row, `\|`) {
+		row = strings.TrimSuffix(row, "|")
+	}
+
+	cells := []string{}
+	cell := &strings.Builder{}
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// markdownFence returns the marker if line opens or closes a fenced
+// code block.
+func markdownFence(line string) (string, bool) {
+	for _, c := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, c) {
+			return line[:len(line)-len(strings.TrimLeft(line, c[:1]))], true
+		}
+	}
+	return "", false
+}
+
+// markdownAnchor returns the anchor GitHub generates for a heading:
+// lowercase letters, digits, hyphens and underscores with spaces
+// replaced by hyphens.
+func markdownAnchor(heading string) string {
+	b := &strings.Builder{}
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package dataparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const markdownTables = "# Status page\n" +
+	"\n" +
+	"| Name | Status |\n" +
+	"|------|:------:|\n" +
+	"| api  | up     |\n" +
+	"| db   |\n" +
+	"\n" +
+	"```\n" +
+	"| in | code |\n" +
+	"|----|------|\n" +
+	"| x  | y    |\n" +
+	"```\n" +
+	"\n" +
+	"## Service Status\n" +
+	"\n" +
+	"Service | Command | Extra\n" +
+	"--- | --- | ---\n" +
+	"grep | `a \\| b` | x | ignored\n" +
+	"ls | **bold** |\n" +
+	"not a row\n"
+
+func readMarkdownTable(t *testing.T, input string, opts ...FromOption) []*Map {
+	t.Helper()
+	results := []*Map{}
+	for m, err := range MarkdownTableAll(strings.NewReader(input), opts...) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	return results
+}
+
+func TestMarkdownTableAll(t *testing.T) {
+	results := readMarkdownTable(t, markdownTables)
+	require.Len(t, results, 2)
+	assert.Equal(t, map[any]any{"Name": "api", "Status": "up"}, results[0].Data)
+	assert.Equal(t, map[any]any{"Name": "db", "Status": ""}, results[1].Data)
+	assert.Equal(t, 5, results[0].Provenance.Line)
+	assert.Equal(t, 1, results[1].Provenance.Index)
+}
+
+func TestMarkdownTableAll_DuplicateHeaders(t *testing.T) {
+	results := readMarkdownTable(t, "| a | a |\n|---|---|\n| 1 | 2 |\n")
+	require.Len(t, results, 1)
+	assert.Equal(t, map[any]any{"a": "1", "a2": "2"}, results[0].Data)
+}
+
+func TestMarkdownTableAll_Select(t *testing.T) {
+	expected := []map[any]any{
+		{"Service": "grep", "Command": "`a | b`", "Extra": "x"},
+		{"Service": "ls", "Command": "**bold**", "Extra": ""},
+	}
+
+	for _, opt := range []FromOption{
+		WithTableIndex(1),
+		WithTableId("service-status"),
+		WithTableCaption("service status"),
+	} {
+		results := readMarkdownTable(t, markdownTables, opt)
+		require.Len(t, results, 2)
+		assert.Equal(t, expected[0], results[0].Data)
+		assert.Equal(t, expected[1], results[1].Data)
+	}
+}
+
+func TestMarkdownTableAll_NotFound(t *testing.T) {
+	for _, opt := range []FromOption{WithTableIndex(2), WithTableId("status")} {
+		for _, err := range MarkdownTableAll(strings.NewReader(markdownTables), opt) {
+			require.ErrorIs(t, err, ErrTableNotFound)
+		}
+	}
+}
+
+func TestMarkdownTableAll_From(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"status.md", "status.txt"} {
+		path := filepath.Join(dir, name)
+		require.Nil(t, os.WriteFile(path, []byte(markdownTables), 0o644))
+
+		count := 0
+		for m, err := range All(path) {
+			require.Nil(t, err)
+			assert.Equal(t, "api", m.Data["Name"])
+			count++
+			break
+		}
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestMarkdownCells(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, markdownCells("| a | b |"))
+	assert.Equal(t, []string{"a", "b"}, markdownCells("a|b"))
+	assert.Equal(t, []string{"a", `b|`}, markdownCells(`| a | b\|`))
+	assert.Equal(t, []string{""}, markdownCells("||"))
+}
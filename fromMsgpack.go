@@ -0,0 +1,174 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// FromMsgpack returns maps parsed from a MessagePack stream which may
+// consist of:
+// 1. A single document
+// 2. A stream of documents
+// 3. An array of documents
+//
+// Arrays are decoded one element at a time like in FromJson.
+//
+// Values keep their MessagePack types: integers are returned with
+// their encoded width, e.g. int8 or uint32, binary data as []byte and
+// timestamps as time.Time. Maps with only string keys are returned as
+// map[string]any, other maps as map[any]any.
+//
+// FromMsgpack is a wrapper around MsgpackAll, returning the results in
+// a channel.
+func FromMsgpack(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromMsgpack(cfg))
+}
+
+// FromMsgpackContext is FromMsgpack with the passed context, see
+// WithContext.
+func FromMsgpackContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromMsgpack(reader, append(opts, WithContext(ctx))...)
+}
+
+// MsgpackAll returns an iterator over the maps parsed from
+// a MessagePack stream, see FromMsgpack.
+func MsgpackAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromMsgpack(cfg)
+}
+
+func fromMsgpack(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		source := &countingReader{Reader: bufio.NewReader(cfg.reader)}
+		decoder := msgpack.NewDecoder(source)
+		decoder.SetMapDecoder(func(d *msgpack.Decoder) (any, error) {
+			m, err := d.DecodeUntypedMap()
+			if err != nil {
+				return nil, err
+			}
+			return stringKeys(m), nil
+		})
+
+		r := binaryReader{cfg: cfg, yield: yield}
+		decode := func() bool {
+			offset := source.n
+			value, err := decoder.DecodeInterface()
+			return r.record(offset, value, err)
+		}
+
+		for {
+			code, err := decoder.PeekCode()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, err)
+				}
+				return
+			}
+
+			if !msgpcode.IsFixedArray(code) && code != msgpcode.Array16 && code != msgpcode.Array32 {
+				if !decode() {
+					return
+				}
+				continue
+			}
+
+			n, err := decoder.DecodeArrayLen()
+			if err != nil {
+				yield(nil, NewErrRecord(r.provenance(source.n), err))
+				return
+			}
+			for range n {
+				if !decode() {
+					return
+				}
+			}
+		}
+	})
+}
+
+// binaryReader yields the values decoded from binary formats as maps.
+type binaryReader struct {
+	cfg   *fromConfig
+	yield func(*Map, error) bool
+	index int
+}
+
+func (r *binaryReader) provenance(offset int64) Provenance {
+	return Provenance{
+		Source: r.cfg.source,
+		Member: r.cfg.member,
+		Index:  r.index,
+		Offset: offset,
+	}
+}
+
+// record yields value as a map or an error if the value failed to
+// decode. Binary formats cannot resynchronize after decoding errors,
+// values that are not maps are handled according to the error policy.
+func (r *binaryReader) record(offset int64, value any, err error) bool {
+	p := r.provenance(offset)
+	r.index++
+	if err != nil {
+		r.yield(nil, NewErrRecord(p, err))
+		return false
+	}
+
+	m, err := NewMap(value)
+	if err != nil {
+		return r.cfg.recordError(r.yield,
+			NewErrRecord(p, fmt.Errorf("dataparse: unhandled type %T: %w", value, err)))
+	}
+	m.Provenance = p
+	return r.yield(m, nil)
+}
+
+// stringKeys returns m as map[string]any if all keys are strings.
+func stringKeys(m map[any]any) any {
+	converted := make(map[string]any, len(m))
+	for key, value := range m {
+		s, ok := key.(string)
+		if !ok {
+			return m
+		}
+		converted[s] = value
+	}
+	return converted
+}
+
+// countingReader counts the bytes read from a buffered reader.
+type countingReader struct {
+	*bufio.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.Reader.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+func (c *countingReader) UnreadByte() error {
+	err := c.Reader.UnreadByte()
+	if err == nil {
+		c.n--
+	}
+	return err
+}
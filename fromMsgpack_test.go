@@ -0,0 +1,84 @@
+package dataparse
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func encodeMsgpack(t *testing.T, values ...any) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	enc := msgpack.NewEncoder(buf)
+	for _, v := range values {
+		require.Nil(t, enc.Encode(v))
+	}
+	return buf.Bytes()
+}
+
+func TestMsgpackAll(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := encodeMsgpack(t,
+		map[string]any{"id": int8(1), "raw": []byte{0x01, 0x02}, "ts": ts},
+		[]any{
+			map[string]any{"id": uint32(2)},
+			map[string]any{"id": int64(3), "nested": map[string]any{"a": "b"}},
+		},
+		map[any]any{int64(4): "int key", "id": 4},
+	)
+
+	results := []*Map{}
+	for m, err := range MsgpackAll(bytes.NewReader(input)) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	require.Len(t, results, 4)
+
+	assert.Equal(t, int8(1), results[0].Data["id"])
+	assert.Equal(t, []byte{0x01, 0x02}, results[0].Data["raw"])
+	assert.Equal(t, ts, results[0].MustGet("ts").MustTime().UTC())
+
+	assert.Equal(t, uint32(2), results[1].Data["id"])
+	assert.Equal(t, int64(3), results[2].MustGet("id").MustInt64())
+	assert.Equal(t, "b", results[2].MustGet("nested.a").MustString())
+	assert.Equal(t, "int key", results[3].MustGet(int64(4)).MustString())
+
+	offsets := []int64{}
+	indexes := []int{}
+	for _, m := range results {
+		offsets = append(offsets, m.Provenance.Offset)
+		indexes = append(indexes, m.Provenance.Index)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3}, indexes)
+	// the array header is one byte
+	first := int64(len(encodeMsgpack(t, map[string]any{"id": int8(1), "raw": []byte{0x01, 0x02}, "ts": ts})))
+	assert.Equal(t, []int64{0, first + 1}, offsets[:2])
+}
+
+func TestMsgpackAll_Errors(t *testing.T) {
+	input := encodeMsgpack(t, []any{map[string]any{"id": 1}, "scalar", map[string]any{"id": 3}})
+
+	ids, errs := collect(t, MsgpackAll(bytes.NewReader(input), WithErrorPolicy(ErrorPolicySkip)))
+	assert.Equal(t, []int{1, 3}, ids)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "record 1")
+
+	// truncated input
+	_, errs = collect(t, MsgpackAll(bytes.NewReader(input[:len(input)-2])))
+	require.Len(t, errs, 1)
+}
+
+func TestFrom_Msgpack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.msgpack")
+	require.Nil(t, os.WriteFile(path, encodeMsgpack(t, map[string]any{"id": 1}), 0o644))
+
+	m, err := FromSingle(path)
+	require.Nil(t, err)
+	assert.Equal(t, 1, m.MustGet("id").MustInt())
+}
@@ -0,0 +1,486 @@
+package dataparse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math/big"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/deprecated"
+	parquetformat "github.com/parquet-go/parquet-go/format"
+)
+
+// FromParquet returns maps read from the rows of a Parquet file.
+//
+// Row groups are read one after another and rows are decoded in small
+// batches, so memory usage is proportional to the size of the pages
+// of a row group instead of the size of the file.
+// Columns can be selected with WithProjection, the other columns are
+// not decoded.
+//
+// Values are returned with Go types matching their logical types:
+//   - strings, enums and JSON as string
+//   - timestamps and dates as time.Time in UTC
+//   - times as time.Duration since midnight
+//   - decimals as *big.Rat
+//   - integers with their annotated width, e.g. int8 or uint32
+//   - lists as []any and maps as map[string]any, or map[any]any if
+//     the keys are not strings
+//   - groups as map[string]any
+//
+// Null values are returned as nil.
+//
+// The Parquet format requires random access. If the reader does not
+// implement io.ReaderAt the input is read into memory first.
+//
+// FromParquet is a wrapper around ParquetAll, returning the results in
+// a channel.
+func FromParquet(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromParquet(cfg))
+}
+
+// FromParquetContext is FromParquet with the passed context, see
+// WithContext.
+func FromParquetContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromParquet(reader, append(opts, WithContext(ctx))...)
+}
+
+// ParquetAll returns an iterator over the maps read from the rows of
+// a Parquet file, see FromParquet.
+func ParquetAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromParquet(cfg)
+}
+
+func fromParquet(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		readerAt, size, ok := readerAtSize(cfg.reader)
+		if !ok {
+			b, err := io.ReadAll(cfg.reader)
+			if err != nil {
+				yield(nil, fmt.Errorf("dataparse: error reading parquet file: %w", err))
+				return
+			}
+			readerAt = bytes.NewReader(b)
+			size = int64(len(b))
+		}
+
+		f, err := parquet.OpenFile(readerAt, size)
+		if err != nil {
+			yield(nil, fmt.Errorf("dataparse: error opening parquet file: %w", err))
+			return
+		}
+
+		schema, conv, err := parquetProjection(f.Schema(), cfg.projection)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		r := &parquetReader{
+			cfg:   cfg,
+			yield: yield,
+			root:  newParquetNode(schema, "", 0, 0, new(int)),
+		}
+		for _, rowGroup := range f.RowGroups() {
+			if conv != nil {
+				rowGroup = parquet.ConvertRowGroup(rowGroup, conv)
+			}
+			if !r.rowGroup(rowGroup) {
+				return
+			}
+		}
+	})
+}
+
+// parquetProjection returns the schema with only the projected
+// top-level columns and the conversion from the file schema.
+// Without projection the file schema is returned as is.
+func parquetProjection(schema *parquet.Schema, projection []string) (parquet.Node, parquet.Conversion, error) {
+	if len(projection) == 0 {
+		return schema, nil, nil
+	}
+
+	fields := map[string]parquet.Field{}
+	for _, field := range schema.Fields() {
+		fields[field.Name()] = field
+	}
+
+	group := parquet.Group{}
+	for _, name := range projection {
+		field, ok := fields[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("dataparse: projected column %q not found in parquet schema", name)
+		}
+		group[name] = field
+	}
+
+	projected := parquet.NewSchema(schema.Name(), group)
+	conv, err := parquet.Convert(projected, schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dataparse: error projecting parquet schema: %w", err)
+	}
+	return projected, conv, nil
+}
+
+// parquetReader reads the rows of row groups and assembles them into
+// maps.
+type parquetReader struct {
+	cfg   *fromConfig
+	yield func(*Map, error) bool
+	root  *parquetNode
+	index int
+
+	// columns holds the values of the current row per leaf column and
+	// positions the values that were already assembled.
+	columns   [][]parquet.Value
+	positions []int
+}
+
+// parquetRowBatch is the number of rows read from a row group at once.
+const parquetRowBatch = 128
+
+func (r *parquetReader) rowGroup(rowGroup parquet.RowGroup) bool {
+	rows := rowGroup.Rows()
+	defer rows.Close()
+
+	buf := make([]parquet.Row, parquetRowBatch)
+	for {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			if !r.row(row) {
+				return false
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				r.yield(nil, NewErrRecord(r.provenance(), err))
+				return false
+			}
+			return true
+		}
+	}
+}
+
+func (r *parquetReader) provenance() Provenance {
+	return Provenance{
+		Source: r.cfg.source,
+		Member: r.cfg.member,
+		Index:  r.index,
+	}
+}
+
+// row assembles the values of row into a map and yields it.
+func (r *parquetReader) row(row parquet.Row) bool {
+	r.columns = r.columns[:0]
+	r.positions = r.positions[:0]
+	row.Range(func(_ int, values []parquet.Value) bool {
+		r.columns = append(r.columns, values)
+		r.positions = append(r.positions, 0)
+		return true
+	})
+
+	p := r.provenance()
+	r.index++
+
+	m, err := NewMap(r.group(r.root))
+	if err != nil {
+		return r.cfg.recordError(r.yield, NewErrRecord(p, err))
+	}
+	m.Provenance = p
+	return r.yield(m, nil)
+}
+
+// parquetNode is a node of the schema annotated with the maximum
+// definition and repetition levels of its values and the leaf columns
+// it spans.
+type parquetNode struct {
+	name   string
+	node   parquet.Node
+	def    int
+	rep    int
+	leaves []int
+	fields []*parquetNode
+}
+
+func newParquetNode(node parquet.Node, name string, def, rep int, column *int) *parquetNode {
+	if node.Optional() {
+		def++
+	}
+	if node.Repeated() {
+		def++
+		rep++
+	}
+
+	n := &parquetNode{name: name, node: node, def: def, rep: rep}
+	if node.Leaf() {
+		n.leaves = []int{*column}
+		*column++
+		return n
+	}
+
+	for _, field := range node.Fields() {
+		child := newParquetNode(field, field.Name(), def, rep, column)
+		n.fields = append(n.fields, child)
+		n.leaves = append(n.leaves, child.leaves...)
+	}
+	return n
+}
+
+// peek returns the next value of the first leaf column of n.
+func (r *parquetReader) peek(n *parquetNode) (parquet.Value, bool) {
+	if len(n.leaves) == 0 {
+		return parquet.Value{}, false
+	}
+	column := n.leaves[0]
+	if column >= len(r.columns) || r.positions[column] >= len(r.columns[column]) {
+		return parquet.Value{}, false
+	}
+	return r.columns[column][r.positions[column]], true
+}
+
+// skip consumes the values of an absent node, which is a single value
+// in each of its leaf columns.
+func (r *parquetReader) skip(n *parquetNode) {
+	for _, column := range n.leaves {
+		if column < len(r.positions) {
+			r.positions[column]++
+		}
+	}
+}
+
+// value assembles the next value of the field n.
+func (r *parquetReader) value(n *parquetNode) any {
+	if n.node.Repeated() {
+		return r.list(n, func() any { return r.present(n) })
+	}
+
+	if n.node.Optional() {
+		if v, ok := r.peek(n); !ok || v.DefinitionLevel() < n.def {
+			r.skip(n)
+			return nil
+		}
+	}
+	return r.present(n)
+}
+
+// present assembles the value of n, which is known to be defined.
+func (r *parquetReader) present(n *parquetNode) any {
+	if n.node.Leaf() {
+		column := n.leaves[0]
+		if column >= len(r.columns) || r.positions[column] >= len(r.columns[column]) {
+			return nil
+		}
+		v := r.columns[column][r.positions[column]]
+		r.positions[column]++
+		return parquetValue(n.node, v)
+	}
+
+	var logical parquetformat.LogicalTypeValue
+	if lt := n.node.Type().LogicalType(); lt != nil {
+		logical = lt.Value
+	}
+
+	switch logical.(type) {
+	case *parquetformat.ListType:
+		if len(n.fields) == 1 && n.fields[0].node.Repeated() {
+			return r.listElements(n.fields[0])
+		}
+	case *parquetformat.MapType:
+		if len(n.fields) == 1 && n.fields[0].node.Repeated() && len(n.fields[0].fields) == 2 {
+			return r.mapEntries(n.fields[0])
+		}
+	}
+	return r.group(n)
+}
+
+// group assembles the fields of n into a map.
+func (r *parquetReader) group(n *parquetNode) map[string]any {
+	m := make(map[string]any, len(n.fields))
+	for _, field := range n.fields {
+		m[field.name] = r.value(field)
+	}
+	return m
+}
+
+// list assembles the repeated node n into a slice, reading the
+// elements with element.
+// An undefined first value denotes an empty list, otherwise elements
+// are read as long as the next value repeats at the level of n.
+func (r *parquetReader) list(n *parquetNode, element func() any) []any {
+	list := []any{}
+	if v, ok := r.peek(n); !ok || v.DefinitionLevel() < n.def {
+		r.skip(n)
+		return list
+	}
+
+	for {
+		list = append(list, element())
+		if v, ok := r.peek(n); !ok || v.RepetitionLevel() != n.rep {
+			return list
+		}
+	}
+}
+
+// listElements assembles the repeated node of a LIST annotated group.
+// With the three-level structure the repeated group wraps a single
+// element field, which is unwrapped.
+func (r *parquetReader) listElements(repeated *parquetNode) []any {
+	return r.list(repeated, func() any {
+		if len(repeated.fields) == 1 {
+			return r.value(repeated.fields[0])
+		}
+		return r.present(repeated)
+	})
+}
+
+// mapEntries assembles the repeated key-value group of a MAP
+// annotated group.
+func (r *parquetReader) mapEntries(repeated *parquetNode) any {
+	key, value := repeated.fields[0], repeated.fields[1]
+	if key.name == "value" || value.name == "key" {
+		key, value = value, key
+	}
+
+	m := map[any]any{}
+	r.list(repeated, func() any {
+		k := r.value(key)
+		m[k] = r.value(value)
+		return nil
+	})
+	return stringKeys(m)
+}
+
+// parquetValue converts the leaf value v to the Go type matching the
+// logical type of node.
+func parquetValue(node parquet.Node, v parquet.Value) any {
+	if v.IsNull() {
+		return nil
+	}
+
+	typ := node.Type()
+	if lt := typ.LogicalType(); lt != nil {
+		switch logical := lt.Value.(type) {
+		case *parquetformat.StringType, *parquetformat.EnumType, *parquetformat.JsonType:
+			return string(v.ByteArray())
+		case *parquetformat.UUIDType:
+			b := v.ByteArray()
+			if len(b) == 16 {
+				return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+			}
+		case *parquetformat.DecimalType:
+			return parquetDecimal(v, logical.Scale)
+		case *parquetformat.DateType:
+			return time.Unix(int64(v.Int32())*24*60*60, 0).UTC()
+		case *parquetformat.TimestampType:
+			return parquetTimestamp(v.Int64(), logical.Unit)
+		case *parquetformat.TimeType:
+			if v.Kind() == parquet.Int32 {
+				return time.Duration(v.Int32()) * time.Millisecond
+			}
+			return time.Duration(v.Int64()) * parquetUnit(logical.Unit)
+		case *parquetformat.IntType:
+			return parquetInt(v, logical)
+		}
+	}
+
+	if ct := typ.ConvertedType(); ct != nil {
+		switch *ct {
+		case deprecated.UTF8, deprecated.Enum, deprecated.Json:
+			return string(v.ByteArray())
+		}
+	}
+
+	switch v.Kind() {
+	case parquet.Boolean:
+		return v.Boolean()
+	case parquet.Int32:
+		return v.Int32()
+	case parquet.Int64:
+		return v.Int64()
+	case parquet.Int96:
+		return parquetInt96(v.Int96())
+	case parquet.Float:
+		return v.Float()
+	case parquet.Double:
+		return v.Double()
+	default:
+		// byte arrays reference the buffers of the reader
+		return bytes.Clone(v.ByteArray())
+	}
+}
+
+// parquetDecimal returns the decimal in v with the given scale.
+// Decimals are stored as unscaled integers or as big-endian two's
+// complement byte arrays.
+func parquetDecimal(v parquet.Value, scale int32) *big.Rat {
+	unscaled := new(big.Int)
+	switch v.Kind() {
+	case parquet.Int32:
+		unscaled.SetInt64(int64(v.Int32()))
+	case parquet.Int64:
+		unscaled.SetInt64(v.Int64())
+	default:
+		b := v.ByteArray()
+		unscaled.SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+		}
+	}
+
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	return new(big.Rat).SetFrac(unscaled, denom)
+}
+
+func parquetTimestamp(n int64, unit parquetformat.TimeUnit) time.Time {
+	return time.Unix(0, 0).Add(time.Duration(n) * parquetUnit(unit)).UTC()
+}
+
+func parquetUnit(unit parquetformat.TimeUnit) time.Duration {
+	switch unit.Value.(type) {
+	case *parquetformat.MilliSeconds:
+		return time.Millisecond
+	case *parquetformat.MicroSeconds:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
+
+// parquetInt96 returns the legacy INT96 timestamp i, which holds the
+// nanoseconds of the day and the Julian day.
+func parquetInt96(i deprecated.Int96) time.Time {
+	const julianUnixEpoch = 2440588
+	nanos := int64(i[1])<<32 | int64(i[0])
+	days := int64(i[2]) - julianUnixEpoch
+	return time.Unix(days*24*60*60, nanos).UTC()
+}
+
+func parquetInt(v parquet.Value, logical *parquetformat.IntType) any {
+	switch {
+	case logical.IsSigned && logical.BitWidth == 8:
+		return int8(v.Int32())
+	case logical.IsSigned && logical.BitWidth == 16:
+		return int16(v.Int32())
+	case logical.IsSigned && logical.BitWidth == 32:
+		return v.Int32()
+	case logical.IsSigned:
+		return v.Int64()
+	case logical.BitWidth == 8:
+		return uint8(v.Int32())
+	case logical.BitWidth == 16:
+		return uint16(v.Int32())
+	case logical.BitWidth == 32:
+		return uint32(v.Int32())
+	default:
+		return uint64(v.Int64())
+	}
+}
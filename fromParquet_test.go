@@ -0,0 +1,151 @@
+package dataparse
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type parquetAddress struct {
+	City string  `parquet:"city"`
+	Zip  *string `parquet:"zip,optional"`
+}
+
+type parquetRecord struct {
+	ID      int64            `parquet:"id"`
+	Name    *string          `parquet:"name,optional"`
+	Price   int64            `parquet:"price,decimal(2:18)"`
+	Created time.Time        `parquet:"created,timestamp(millisecond)"`
+	Tags    []string         `parquet:"tags,list"`
+	Counts  map[string]int32 `parquet:"counts"`
+	Address *parquetAddress  `parquet:"address,optional"`
+	Small   int8             `parquet:"small"`
+}
+
+func writeParquet(t *testing.T, rows ...parquetRecord) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	// small row groups to read across row group boundaries
+	w := parquet.NewGenericWriter[parquetRecord](buf, parquet.MaxRowsPerRowGroup(2))
+	_, err := w.Write(rows)
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	return buf.Bytes()
+}
+
+func parquetTestRecords() []parquetRecord {
+	name := "alice"
+	zip := "12345"
+	return []parquetRecord{
+		{
+			ID:      1,
+			Name:    &name,
+			Price:   1250,
+			Created: time.Date(2026, 1, 2, 3, 4, 5, 6e6, time.UTC),
+			Tags:    []string{"a", "b"},
+			Counts:  map[string]int32{"x": 1},
+			Address: &parquetAddress{City: "Berlin", Zip: &zip},
+			Small:   -3,
+		},
+		{
+			ID:      2,
+			Price:   -5,
+			Created: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC),
+			Address: &parquetAddress{City: "Hamburg"},
+		},
+		{
+			ID:    3,
+			Price: 100,
+			Tags:  []string{"c"},
+		},
+	}
+}
+
+func TestParquetAll(t *testing.T) {
+	input := writeParquet(t, parquetTestRecords()...)
+
+	results := []*Map{}
+	for m, err := range ParquetAll(bytes.NewReader(input)) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	require.Len(t, results, 3)
+
+	first := results[0]
+	assert.Equal(t, int64(1), first.Data["id"])
+	assert.Equal(t, "alice", first.Data["name"])
+	assert.Equal(t, big.NewRat(25, 2), first.Data["price"])
+	assert.Equal(t, "12.5", first.MustGet("price").MustString())
+	assert.Equal(t, 12.5, first.MustGet("price").MustFloat64())
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 6e6, time.UTC), first.Data["created"])
+	assert.Equal(t, []any{"a", "b"}, first.Data["tags"])
+	assert.Equal(t, map[string]any{"x": int32(1)}, first.Data["counts"])
+	assert.Equal(t, "Berlin", first.MustGet("address.city").MustString())
+	assert.Equal(t, "12345", first.MustGet("address.zip").MustString())
+	assert.Equal(t, int8(-3), first.Data["small"])
+
+	second := results[1]
+	assert.Nil(t, second.Data["name"])
+	assert.Equal(t, "-0.05", second.MustGet("price").MustString())
+	assert.Equal(t, []any{}, second.Data["tags"])
+	assert.Nil(t, second.MustGet("address.zip").Data)
+
+	third := results[2]
+	assert.Equal(t, 1, third.MustGet("price").MustInt())
+	assert.Equal(t, []any{"c"}, third.Data["tags"])
+	assert.Nil(t, third.Data["address"])
+
+	for i, m := range results {
+		assert.Equal(t, i, m.Provenance.Index)
+	}
+}
+
+func TestParquetAll_Projection(t *testing.T) {
+	input := writeParquet(t, parquetTestRecords()...)
+
+	results := []*Map{}
+	for m, err := range ParquetAll(bytes.NewReader(input), WithProjection("name", "id", "address")) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	require.Len(t, results, 3)
+
+	assert.Equal(t, map[any]any{
+		"id":      int64(1),
+		"name":    "alice",
+		"address": map[string]any{"city": "Berlin", "zip": "12345"},
+	}, results[0].Data)
+	assert.Equal(t, map[any]any{
+		"id":      int64(3),
+		"name":    nil,
+		"address": nil,
+	}, results[2].Data)
+}
+
+func TestParquetAll_ProjectionUnknownColumn(t *testing.T) {
+	input := writeParquet(t, parquetTestRecords()...)
+
+	for _, err := range ParquetAll(bytes.NewReader(input), WithProjection("missing")) {
+		require.ErrorContains(t, err, `projected column "missing" not found`)
+	}
+}
+
+func TestFrom_Parquet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.parquet")
+	require.Nil(t, os.WriteFile(path, writeParquet(t, parquetTestRecords()...), 0o644))
+
+	count := 0
+	for m, err := range All(path) {
+		require.Nil(t, err)
+		assert.Equal(t, path, m.Provenance.Source)
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,179 @@
+package dataparse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// FromProperties returns a single map parsed from a Java properties
+// file.
+//
+// Keys and values are separated by "=", ":" or whitespace. Lines
+// starting with "#" or "!" are comments and lines ending in an odd
+// number of backslashes are continued on the next line.
+// The escapes \t, \n, \r, \f and \uXXXX are decoded, all other
+// escaped characters are taken literally, e.g. "\=" in keys.
+//
+// Dotted keys are nested, so "db.host" can be retrieved with
+// Get("db.host") and the map under "db" converts to a struct.
+// Keys that are also the prefix of other keys, e.g.
+// "log4j.appender.stdout" and "log4j.appender.stdout.layout", keep
+// their value and the longer keys are stored as dotted keys next to
+// it, which Get resolves the same way.
+//
+// Malformed lines are handled according to the error policy.
+//
+// FromProperties is a wrapper around PropertiesAll, returning the
+// results in a channel.
+func FromProperties(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromProperties(cfg))
+}
+
+// FromPropertiesContext is FromProperties with the passed context, see
+// WithContext.
+func FromPropertiesContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromProperties(reader, append(opts, WithContext(ctx))...)
+}
+
+// PropertiesAll returns an iterator over the map parsed from
+// a properties file, see FromProperties.
+func PropertiesAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromProperties(cfg)
+}
+
+func fromProperties(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		m := NewEmptyMap()
+		m.Provenance = Provenance{Source: cfg.source, Member: cfg.member}
+		root := map[string]any{}
+
+		scanner := bufio.NewScanner(cfg.reader)
+		line := 0
+		for scanner.Scan() {
+			line++
+			start := line
+			text := strings.TrimLeft(scanner.Text(), " \t\f")
+			if text == "" || text[0] == '#' || text[0] == '!' {
+				continue
+			}
+
+			for continuesLine(text) && scanner.Scan() {
+				line++
+				text = text[:len(text)-1] + strings.TrimLeft(scanner.Text(), " \t\f")
+			}
+
+			key, value, err := parseProperty(text)
+			if err != nil {
+				p := m.Provenance
+				p.Line = start
+				if !cfg.recordError(yield, NewErrRecord(p, err)) {
+					return
+				}
+				continue
+			}
+			setNested(root, strings.Split(key, "."), value)
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, NewErrRecord(m.Provenance, err))
+			return
+		}
+
+		for key, value := range root {
+			m.Data[key] = value
+		}
+		yield(m, nil)
+	})
+}
+
+// continuesLine returns true if text ends in an odd number of
+// backslashes.
+func continuesLine(text string) bool {
+	count := 0
+	for i := len(text) - 1; i >= 0 && text[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// parseProperty splits a logical line into the unescaped key and
+// value.
+func parseProperty(text string) (string, string, error) {
+	// find the first unescaped separator
+	end := len(text)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if text[i] == '=' || text[i] == ':' || text[i] == ' ' || text[i] == '\t' || text[i] == '\f' {
+			end = i
+			break
+		}
+	}
+
+	key, err := unescapeProperty(text[:end])
+	if err != nil {
+		return "", "", err
+	}
+
+	// the separator may be surrounded by whitespace
+	rest := strings.TrimLeft(text[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// unescapeProperty decodes the escapes in a key or value.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	b := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("dataparse: malformed \\u escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("dataparse: malformed \\u escape in %q: %w", s, err)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,102 @@
+package dataparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const propertiesInput = `# comment
+! another comment
+db.host = db.example.com
+db.port: 5432
+message   Hello \
+          World
+path=C:\\temp
+escaped\=key = value
+unicode = caf\u00e9
+tab = a\tb
+empty
+`
+
+func TestPropertiesAll(t *testing.T) {
+	m, err := single(PropertiesAll(strings.NewReader(propertiesInput)))
+	require.Nil(t, err)
+
+	assert.Equal(t, "db.example.com", m.MustGet("db.host").MustString())
+	assert.Equal(t, 5432, m.MustGet("db.port").MustInt())
+	assert.Equal(t, "Hello World", m.MustGet("message").MustString())
+	assert.Equal(t, `C:\temp`, m.MustGet("path").MustString())
+	assert.Equal(t, "value", m.MustGet("escaped=key").MustString())
+	assert.Equal(t, "café", m.MustGet("unicode").MustString())
+	assert.Equal(t, "a\tb", m.MustGet("tab").MustString())
+	assert.Equal(t, "", m.MustGet("empty").MustString())
+
+	type db struct {
+		Host string `dataparse:"host"`
+		Port int    `dataparse:"port"`
+	}
+	var dbConfig db
+	dbMap, err := m.Map("db")
+	require.Nil(t, err)
+	require.Nil(t, dbMap.To(&dbConfig))
+	assert.Equal(t, db{Host: "db.example.com", Port: 5432}, dbConfig)
+}
+
+func TestFrom_Properties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.properties")
+	require.Nil(t, os.WriteFile(path, []byte(propertiesInput), 0o644))
+
+	m, err := FromSingle(path)
+	require.Nil(t, err)
+	assert.Equal(t, "db.example.com", m.MustGet("db.host").MustString())
+}
+
+func TestPropertiesAll_Malformed(t *testing.T) {
+	input := "a = 1\nbroken = \\u00zz\nc = 3\nalso = \\u12\n"
+
+	_, err := single(PropertiesAll(strings.NewReader(input)))
+	assert.ErrorContains(t, err, "line 2")
+
+	results := []*Map{}
+	errs := []error{}
+	for m, err := range PropertiesAll(strings.NewReader(input), WithErrorPolicy(ErrorPolicySkip)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, m)
+	}
+	assert.Len(t, errs, 2)
+	require.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].MustGet("a").MustInt())
+	assert.Equal(t, 3, results[0].MustGet("c").MustInt())
+}
+
+func TestPropertiesAll_ValueAndPrefix(t *testing.T) {
+	for _, input := range []string{
+		"log4j.appender.stdout = org.apache.log4j.ConsoleAppender\n" +
+			"log4j.appender.stdout.layout = org.apache.log4j.PatternLayout\n" +
+			"log4j.appender.stdout.layout.ConversionPattern = %m%n\n",
+		// the same keys in reverse order
+		"log4j.appender.stdout.layout.ConversionPattern = %m%n\n" +
+			"log4j.appender.stdout.layout = org.apache.log4j.PatternLayout\n" +
+			"log4j.appender.stdout = org.apache.log4j.ConsoleAppender\n",
+	} {
+		m, err := single(PropertiesAll(strings.NewReader(input)))
+		require.Nil(t, err)
+
+		assert.Equal(t, "org.apache.log4j.ConsoleAppender", m.MustGet("log4j.appender.stdout").MustString())
+		assert.Equal(t, "org.apache.log4j.PatternLayout", m.MustGet("log4j.appender.stdout.layout").MustString())
+		assert.Equal(t, "%m%n", m.MustGet("log4j.appender.stdout.layout.ConversionPattern").MustString())
+		assert.Equal(t, map[string]any{
+			"stdout":                          "org.apache.log4j.ConsoleAppender",
+			"stdout.layout":                   "org.apache.log4j.PatternLayout",
+			"stdout.layout.ConversionPattern": "%m%n",
+		}, m.MustGet("log4j.appender").Data)
+	}
+}
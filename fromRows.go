@@ -0,0 +1,163 @@
+package dataparse
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// FromRows returns maps read from the rows of a database query, with
+// the column names as keys.
+//
+// The values returned by drivers are normalized to work with Value and
+// Map.To regardless of the driver:
+//   - text returned as []byte is converted to string, binary columns
+//     like BLOB or BYTEA are kept as []byte
+//   - integer and floating point columns returned as text are parsed
+//     into int64 and float64, decimals are kept as strings to retain
+//     their precision
+//   - date and time columns returned as text are parsed into
+//     time.Time, see ParseTime
+//   - values implementing driver.Valuer like sql.NullString are
+//     replaced by their value
+//   - NULL is returned as nil
+//
+// If multiple columns have the same name, e.g. in joins, the value of
+// the last column is used. Alias the columns in the query to keep all
+// of them.
+//
+// The rows are closed once reading is done.
+//
+// FromRows is a wrapper around RowsAll, returning the results in
+// a channel.
+func FromRows(rows *sql.Rows, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	return cfg.channel(fromRows(cfg, rows))
+}
+
+// FromRowsContext is FromRows with the passed context, see WithContext.
+func FromRowsContext(ctx context.Context, rows *sql.Rows, opts ...FromOption) chan FromResult {
+	return FromRows(rows, append(opts, WithContext(ctx))...)
+}
+
+// RowsAll returns an iterator over the maps read from the rows of
+// a database query, see FromRows.
+func RowsAll(rows *sql.Rows, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	return fromRows(cfg, rows)
+}
+
+func fromRows(cfg *fromConfig, rows *sql.Rows) iter.Seq2[*Map, error] {
+	cfg.closers = append(cfg.closers, rows.Close)
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		columns, err := rows.ColumnTypes()
+		if err != nil {
+			yield(nil, fmt.Errorf("dataparse: error reading column types: %w", err))
+			return
+		}
+
+		values := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		for index := 0; rows.Next(); index++ {
+			p := Provenance{
+				Source: cfg.source,
+				Member: cfg.member,
+				Index:  index,
+			}
+
+			if err := rows.Scan(dest...); err != nil {
+				if !cfg.recordError(yield, NewErrRecord(p, err)) {
+					return
+				}
+				continue
+			}
+
+			m := NewEmptyMap()
+			m.Provenance = p
+			for i, column := range columns {
+				value, err := sqlValue(values[i], column.DatabaseTypeName())
+				if err != nil {
+					err = fmt.Errorf("dataparse: error normalizing column %q: %w", column.Name(), err)
+					m = nil
+					if !cfg.recordError(yield, NewErrRecord(p, err)) {
+						return
+					}
+					break
+				}
+				m.Data[column.Name()] = value
+			}
+			if m != nil && !yield(m, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("dataparse: error reading rows: %w", err))
+		}
+	})
+}
+
+// sqlValue normalizes the value of a column with the database type,
+// see FromRows.
+func sqlValue(value any, databaseType string) (any, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+
+	databaseType = strings.ToUpper(databaseType)
+	switch typed := value.(type) {
+	case []byte:
+		if sqlBinary(databaseType) {
+			return bytes.Clone(typed), nil
+		}
+		return sqlText(string(typed), databaseType), nil
+	case string:
+		return sqlText(typed, databaseType), nil
+	default:
+		return value, nil
+	}
+}
+
+// sqlText parses text returned for numeric and temporal columns.
+// If the text does not parse it is returned unchanged.
+func sqlText(s, databaseType string) any {
+	switch {
+	case strings.Contains(databaseType, "INT"):
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case strings.Contains(databaseType, "FLOAT"), strings.Contains(databaseType, "DOUBLE"),
+		databaseType == "REAL":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case strings.Contains(databaseType, "DATE"), strings.Contains(databaseType, "TIME"):
+		if t, err := ParseTime(s); err == nil {
+			return t
+		}
+	}
+	return s
+}
+
+// sqlBinary returns true for the database types of binary columns.
+func sqlBinary(databaseType string) bool {
+	switch {
+	case strings.Contains(databaseType, "BLOB"), strings.Contains(databaseType, "BINARY"):
+		return true
+	default:
+		return databaseType == "BYTEA" || databaseType == "IMAGE" || databaseType == "RAW"
+	}
+}
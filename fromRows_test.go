@@ -0,0 +1,148 @@
+package dataparse
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDriver is an in-process driver returning fixed rows for every
+// query, with values as drivers using text protocols return them.
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(string) (driver.Stmt, error) { return testStmt{}, nil }
+func (testConn) Close() error                        { return nil }
+func (testConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type testStmt struct{}
+
+func (testStmt) Close() error                               { return nil }
+func (testStmt) NumInput() int                              { return -1 }
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("not supported") }
+func (testStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &testRows{
+		columns: []string{"id", "name", "amount", "score", "created", "avatar", "deleted"},
+		types:   []string{"BIGINT", "VARCHAR", "DECIMAL", "DOUBLE", "TIMESTAMP", "BLOB", "DATETIME"},
+		rows: [][]driver.Value{
+			{[]byte("1"), []byte("alice"), []byte("12.50"), []byte("0.5"), []byte("2026-01-02 03:04:05"), []byte{0x00, 0x01}, nil},
+			{int64(2), "bob", "7", 1.5, time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC), nil, []byte("2026-03-04T05:06:07Z")},
+		},
+	}, nil
+}
+
+type testRows struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+func (r *testRows) Columns() []string                       { return r.columns }
+func (r *testRows) Close() error                            { return nil }
+func (r *testRows) ColumnTypeDatabaseTypeName(i int) string { return r.types[i] }
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("dataparse-test", testDriver{})
+}
+
+func queryTestRows(t *testing.T) *sql.Rows {
+	t.Helper()
+	db, err := sql.Open("dataparse-test", "")
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	rows, err := db.QueryContext(context.Background(), "SELECT * FROM users")
+	require.Nil(t, err)
+	return rows
+}
+
+func TestRowsAll(t *testing.T) {
+	results := []*Map{}
+	for m, err := range RowsAll(queryTestRows(t)) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	require.Len(t, results, 2)
+
+	assert.Equal(t, map[any]any{
+		"id":      int64(1),
+		"name":    "alice",
+		"amount":  "12.50",
+		"score":   0.5,
+		"created": time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		"avatar":  []byte{0x00, 0x01},
+		"deleted": nil,
+	}, results[0].Data)
+	assert.Equal(t, 1, results[1].Provenance.Index)
+	assert.Equal(t, time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC), results[1].MustGet("deleted").MustTime())
+}
+
+func TestRowsAll_To(t *testing.T) {
+	type user struct {
+		ID      int       `dataparse:"id"`
+		Name    string    `dataparse:"name"`
+		Amount  float64   `dataparse:"amount"`
+		Score   float32   `dataparse:"score"`
+		Created time.Time `dataparse:"created"`
+	}
+
+	users := []user{}
+	for m, err := range RowsAll(queryTestRows(t)) {
+		require.Nil(t, err)
+		var u user
+		require.Nil(t, m.To(&u))
+		users = append(users, u)
+	}
+
+	assert.Equal(t, []user{
+		{ID: 1, Name: "alice", Amount: 12.5, Score: 0.5, Created: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{ID: 2, Name: "bob", Amount: 7, Score: 1.5, Created: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)},
+	}, users)
+}
+
+func TestRowsAll_ClosesRows(t *testing.T) {
+	rows := queryTestRows(t)
+	for _, err := range RowsAll(rows) {
+		require.Nil(t, err)
+		break
+	}
+	// the second row is not returned after the rows were closed
+	assert.False(t, rows.Next())
+	assert.Nil(t, rows.Err())
+}
+
+func TestSqlValue(t *testing.T) {
+	v, err := sqlValue(sql.NullString{String: "x", Valid: true}, "TEXT")
+	require.Nil(t, err)
+	assert.Equal(t, "x", v)
+
+	v, err = sqlValue(sql.NullInt64{}, "INTEGER")
+	require.Nil(t, err)
+	assert.Nil(t, v)
+
+	v, err = sqlValue([]byte("not a number"), "INTEGER")
+	require.Nil(t, err)
+	assert.Equal(t, "not a number", v)
+
+	v, err = sqlValue([]byte{0xff}, "bytea")
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0xff}, v)
+}
@@ -0,0 +1,61 @@
+package dataparse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// FromToml returns a single map parsed from a TOML document.
+//
+// Tables are nested maps and arrays of tables are lists of maps.
+// Integers are returned as int64, floats as float64 and offset date
+// times as time.Time.
+//
+// FromToml is a wrapper around TomlAll, returning the results in
+// a channel.
+func FromToml(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromToml(cfg))
+}
+
+// FromTomlContext is FromToml with the passed context, see WithContext.
+func FromTomlContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromToml(reader, append(opts, WithContext(ctx))...)
+}
+
+// TomlAll returns an iterator over the map parsed from a TOML document,
+// see FromToml.
+func TomlAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromToml(cfg)
+}
+
+func fromToml(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		p := Provenance{Source: cfg.source, Member: cfg.member}
+
+		data := map[string]any{}
+		if err := toml.NewDecoder(cfg.reader).Decode(&data); err != nil {
+			var decodeErr *toml.DecodeError
+			if errors.As(err, &decodeErr) {
+				p.Line, _ = decodeErr.Position()
+			}
+			yield(nil, NewErrRecord(p, err))
+			return
+		}
+
+		m, err := NewMap(data)
+		if err != nil {
+			yield(nil, NewErrRecord(p, err))
+			return
+		}
+		m.Provenance = p
+		yield(m, nil)
+	})
+}
@@ -0,0 +1,60 @@
+package dataparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tomlInput = `
+title = "inventory"
+updated = 2026-01-02T03:04:05Z
+
+[owner]
+name = "ops"
+
+[[hosts]]
+name = "alpha"
+port = 22
+
+[[hosts]]
+name = "beta"
+port = 2222
+`
+
+func TestTomlAll(t *testing.T) {
+	m, err := single(TomlAll(strings.NewReader(tomlInput)))
+	require.Nil(t, err)
+
+	assert.Equal(t, "inventory", m.MustGet("title").MustString())
+	assert.Equal(t, "ops", m.MustGet("owner.name").MustString())
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), m.MustGet("updated").MustTime())
+
+	hosts, err := m.MustGet("hosts").List()
+	require.Nil(t, err)
+	require.Len(t, hosts, 2)
+	host, err := hosts[1].Map()
+	require.Nil(t, err)
+	assert.Equal(t, "beta", host.MustGet("name").MustString())
+	assert.Equal(t, 2222, host.MustGet("port").MustInt())
+}
+
+func TestFrom_Toml(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	require.Nil(t, os.WriteFile(path, []byte(tomlInput), 0o644))
+
+	m, err := FromSingle(path)
+	require.Nil(t, err)
+	assert.Equal(t, "inventory", m.MustGet("title").MustString())
+	assert.Equal(t, path, m.Provenance.Source)
+}
+
+func TestTomlAll_Malformed(t *testing.T) {
+	_, err := single(TomlAll(strings.NewReader("a = 1\nb = \n")))
+	assert.ErrorContains(t, err, "record 0 line 2")
+}
@@ -0,0 +1,619 @@
+package dataparse
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromXlsx returns maps read from the sheets of an Excel workbook.
+//
+// The first row of a sheet is used as headers unless headers are
+// passed with WithHeaders. Empty rows are skipped.
+//
+// By default only the first sheet is read. Other sheets can be selected
+// with WithSheet or WithSheetIndex, or all sheets can be read with
+// WithAllSheets.
+//
+// Cell values are returned as native types:
+//   - numbers: int64 if the value is integral, float64 otherwise
+//   - numbers formatted as date or time: time.Time
+//   - booleans: bool
+//   - strings and errors: string
+//
+// Formulas are not evaluated, instead the value cached in the workbook
+// is returned.
+//
+// The workbook format requires random access. If the reader does not
+// implement io.ReaderAt the input is read into memory first.
+//
+// FromXlsx is a wrapper around XlsxAll, returning the results in
+// a channel.
+func FromXlsx(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromXlsx(cfg))
+}
+
+// XlsxAll returns an iterator over the maps read from the sheets of an
+// Excel workbook, see FromXlsx.
+func XlsxAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromXlsx(cfg)
+}
+
+func fromXlsx(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		wb, err := openXlsx(cfg.reader)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		sheets, err := wb.selectSheets(cfg)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for _, sheet := range sheets {
+			ok, err := wb.readSheet(cfg, sheet, yield)
+			if err != nil {
+				yield(nil, fmt.Errorf("dataparse: error reading sheet %q: %w", sheet.Name, err))
+				return
+			}
+			if !ok {
+				return
+			}
+		}
+	})
+}
+
+type xlsxSheet struct {
+	Name string
+	path string
+}
+
+type xlsxWorkbook struct {
+	zip           *zip.Reader
+	sheets        []xlsxSheet
+	sharedStrings []string
+	// dateStyles marks the indizes of cell styles that format numbers
+	// as dates or times.
+	dateStyles map[int]bool
+	date1904   bool
+	// index is the index of the next record across all sheets.
+	index int
+}
+
+// readerAtSize returns an io.ReaderAt and the size of the input if the
+// reader supports random access.
+func readerAtSize(reader io.Reader) (io.ReaderAt, int64, bool) {
+	switch typed := reader.(type) {
+	case *os.File:
+		stat, err := typed.Stat()
+		if err != nil || !stat.Mode().IsRegular() {
+			return nil, 0, false
+		}
+		return typed, stat.Size(), true
+	case interface {
+		io.ReaderAt
+		Size() int64
+	}:
+		return typed, typed.Size(), true
+	default:
+		return nil, 0, false
+	}
+}
+
+func openXlsx(reader io.Reader) (*xlsxWorkbook, error) {
+	readerAt, size, ok := readerAtSize(reader)
+	if !ok {
+		b, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("dataparse: error reading workbook: %w", err)
+		}
+		readerAt = bytes.NewReader(b)
+		size = int64(len(b))
+	}
+
+	zr, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, fmt.Errorf("dataparse: error opening workbook: %w", err)
+	}
+
+	wb := &xlsxWorkbook{
+		zip:        zr,
+		dateStyles: map[int]bool{},
+	}
+
+	if err := wb.readWorkbook(); err != nil {
+		return nil, err
+	}
+
+	if err := wb.readSharedStrings(); err != nil {
+		return nil, err
+	}
+
+	if err := wb.readStyles(); err != nil {
+		return nil, err
+	}
+
+	return wb, nil
+}
+
+// decodePart unmarshals the part at the given path into v.
+// If the part does not exist and optional is true no error is returned.
+func (wb *xlsxWorkbook) decodePart(name string, optional bool, v any) error {
+	f, err := wb.zip.Open(name)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("dataparse: error opening %q in workbook: %w", name, err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("dataparse: error decoding %q in workbook: %w", name, err)
+	}
+	return nil
+}
+
+func (wb *xlsxWorkbook) readWorkbook() error {
+	var workbook struct {
+		WorkbookPr struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			// The relationship id is namespaced, matching on the
+			// local name is sufficient.
+			ID string `xml:"id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := wb.decodePart("xl/workbook.xml", false, &workbook); err != nil {
+		return err
+	}
+	wb.date1904 = workbook.WorkbookPr.Date1904 == "1" || workbook.WorkbookPr.Date1904 == "true"
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := wb.decodePart("xl/_rels/workbook.xml.rels", false, &rels); err != nil {
+		return err
+	}
+
+	targets := map[string]string{}
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+
+	for _, sheet := range workbook.Sheets {
+		target, ok := targets[sheet.ID]
+		if !ok {
+			return fmt.Errorf("dataparse: no target found for sheet %q", sheet.Name)
+		}
+		wb.sheets = append(wb.sheets, xlsxSheet{Name: sheet.Name, path: target})
+	}
+
+	return nil
+}
+
+// xlsxRichText is the content of a shared or inline string.
+// Strings with formatting are split into runs.
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (rt xlsxRichText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.T
+	}
+	var sb strings.Builder
+	sb.WriteString(rt.T)
+	for _, run := range rt.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+func (wb *xlsxWorkbook) readSharedStrings() error {
+	var sst struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	if err := wb.decodePart("xl/sharedStrings.xml", true, &sst); err != nil {
+		return err
+	}
+
+	wb.sharedStrings = make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		wb.sharedStrings[i] = item.String()
+	}
+	return nil
+}
+
+func (wb *xlsxWorkbook) readStyles() error {
+	var styles struct {
+		NumFmts []struct {
+			ID         int    `xml:"numFmtId,attr"`
+			FormatCode string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if err := wb.decodePart("xl/styles.xml", true, &styles); err != nil {
+		return err
+	}
+
+	customDateFormats := map[int]bool{}
+	for _, numFmt := range styles.NumFmts {
+		customDateFormats[numFmt.ID] = isDateFormatCode(numFmt.FormatCode)
+	}
+
+	for i, xf := range styles.CellXfs {
+		if isDate, ok := customDateFormats[xf.NumFmtID]; ok {
+			wb.dateStyles[i] = isDate
+			continue
+		}
+		wb.dateStyles[i] = isBuiltinDateFormat(xf.NumFmtID)
+	}
+
+	return nil
+}
+
+// isBuiltinDateFormat returns true if the builtin number format with
+// the given id formats dates or times.
+func isBuiltinDateFormat(id int) bool {
+	switch {
+	case id >= 14 && id <= 22,
+		id >= 27 && id <= 36,
+		id >= 45 && id <= 47,
+		id >= 50 && id <= 58:
+		return true
+	default:
+		return false
+	}
+}
+
+// isDateFormatCode returns true if the custom number format code
+// contains date or time placeholders.
+// Quoted literals, escaped characters and bracketed sections like
+// colors are ignored.
+func isDateFormatCode(code string) bool {
+	inQuote := false
+	inBracket := false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case inQuote:
+			if c == '"' {
+				inQuote = false
+			}
+		case inBracket:
+			if c == ']' {
+				inBracket = false
+			}
+		case c == '"':
+			inQuote = true
+		case c == '[':
+			// elapsed time like [h]:mm is a time format
+			if i+1 < len(code) && strings.ContainsRune("hHmMsS", rune(code[i+1])) {
+				return true
+			}
+			inBracket = true
+		case c == '\\':
+			i++
+		case strings.ContainsRune("dDmMyYhHsS", rune(c)):
+			return true
+		}
+	}
+	return false
+}
+
+func (wb *xlsxWorkbook) selectSheets(cfg *fromConfig) ([]xlsxSheet, error) {
+	switch {
+	case cfg.allSheets:
+		return wb.sheets, nil
+	case cfg.sheetName != "":
+		for _, sheet := range wb.sheets {
+			if sheet.Name == cfg.sheetName {
+				return []xlsxSheet{sheet}, nil
+			}
+		}
+		return nil, fmt.Errorf("dataparse: sheet %q not found in workbook", cfg.sheetName)
+	default:
+		if cfg.sheetIndex < 0 || cfg.sheetIndex >= len(wb.sheets) {
+			return nil, fmt.Errorf("dataparse: sheet index %d out of range, workbook has %d sheets",
+				cfg.sheetIndex, len(wb.sheets))
+		}
+		return []xlsxSheet{wb.sheets[cfg.sheetIndex]}, nil
+	}
+}
+
+type xlsxCell struct {
+	Ref    string       `xml:"r,attr"`
+	Type   string       `xml:"t,attr"`
+	Style  int          `xml:"s,attr"`
+	Value  string       `xml:"v"`
+	Inline xlsxRichText `xml:"is"`
+}
+
+// readSheet yields the rows of the sheet as maps.
+// It returns false if the iteration was stopped.
+func (wb *xlsxWorkbook) readSheet(cfg *fromConfig, sheet xlsxSheet, yield func(*Map, error) bool) (bool, error) {
+	f, err := wb.zip.Open(sheet.path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	headers := cfg.headers
+	decoder := xml.NewDecoder(f)
+	line := 0
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return true, nil
+			}
+			return false, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		line++
+		if ref := rowRef(start); ref != "" {
+			line, err = strconv.Atoi(ref)
+			if err != nil {
+				return false, fmt.Errorf("dataparse: invalid row reference %q: %w", ref, err)
+			}
+		}
+
+		p := Provenance{
+			Source: cfg.source,
+			Member: cfg.member,
+			Index:  wb.index,
+			Line:   line,
+		}
+
+		row, err := wb.readRow(cfg, decoder, start)
+		if err != nil {
+			if !wb.recordError(cfg, yield, sheet, p, err) {
+				return false, nil
+			}
+			continue
+		}
+
+		if len(row) == 0 {
+			continue
+		}
+
+		if len(headers) == 0 {
+			headers = make([]string, len(row))
+			for i, v := range row {
+				headers[i] = NewValue(v).TrimString()
+			}
+			continue
+		}
+
+		if err := checkXlsxRowWidth(row, len(headers), line); err != nil {
+			if !wb.recordError(cfg, yield, sheet, p, err) {
+				return false, nil
+			}
+			continue
+		}
+
+		wb.index++
+		m := NewEmptyMap()
+		m.Provenance = p
+		for i, header := range headers {
+			if i < len(row) {
+				m.Data[header] = row[i]
+			} else {
+				m.Data[header] = nil
+			}
+		}
+		if cfg.allSheets {
+			m.Data[cfg.sheetKey] = sheet.Name
+		}
+		if !yield(m, nil) {
+			return false, nil
+		}
+	}
+}
+
+// recordError yields an error for a malformed row and returns true if
+// reading should continue according to the error policy.
+func (wb *xlsxWorkbook) recordError(cfg *fromConfig, yield func(*Map, error) bool, sheet xlsxSheet, p Provenance, err error) bool {
+	wb.index++
+	return cfg.recordError(yield, NewErrRecord(p, fmt.Errorf("dataparse: error reading sheet %q: %w", sheet.Name, err)))
+}
+
+// checkXlsxRowWidth returns an error if the row has values in cells
+// without a header.
+func checkXlsxRowWidth(row []any, headers int, line int) error {
+	for i := headers; i < len(row); i++ {
+		if row[i] != nil {
+			return fmt.Errorf("dataparse: cell %s%d has no header", xlsxColumnName(i), line)
+		}
+	}
+	return nil
+}
+
+func rowRef(start xml.StartElement) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "r" {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// readRow reads the cells of a row and returns their values.
+// Missing cells are filled with nil, trailing empty cells are dropped.
+func (wb *xlsxWorkbook) readRow(cfg *fromConfig, decoder *xml.Decoder, start xml.StartElement) ([]any, error) {
+	row := []any{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typed := token.(type) {
+		case xml.EndElement:
+			if typed.Name.Local == start.Name.Local {
+				for len(row) > 0 && row[len(row)-1] == nil {
+					row = row[:len(row)-1]
+				}
+				return row, nil
+			}
+		case xml.StartElement:
+			if typed.Name.Local != "c" {
+				continue
+			}
+
+			var cell xlsxCell
+			if err := decoder.DecodeElement(&cell, &typed); err != nil {
+				return nil, err
+			}
+
+			index := len(row)
+			if cell.Ref != "" {
+				index, err = xlsxColumnIndex(cell.Ref)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			value, err := wb.cellValue(cfg, cell)
+			if err != nil {
+				return nil, fmt.Errorf("dataparse: error reading cell %s: %w", cell.Ref, err)
+			}
+
+			for len(row) <= index {
+				row = append(row, nil)
+			}
+			row[index] = value
+		}
+	}
+}
+
+func (wb *xlsxWorkbook) cellValue(cfg *fromConfig, cell xlsxCell) (any, error) {
+	var s string
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(cell.Value)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= len(wb.sharedStrings) {
+			return nil, fmt.Errorf("dataparse: shared string index %d out of range", i)
+		}
+		s = wb.sharedStrings[i]
+	case "inlineStr":
+		s = cell.Inline.String()
+	case "str", "e":
+		s = cell.Value
+	case "b":
+		return cell.Value == "1", nil
+	case "d":
+		return ParseTime(cell.Value)
+	default:
+		if cell.Value == "" {
+			return nil, nil
+		}
+		f, err := strconv.ParseFloat(cell.Value, 64)
+		if err != nil {
+			return nil, err
+		}
+		if wb.dateStyles[cell.Style] {
+			return xlsxTime(f, wb.date1904), nil
+		}
+		if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return int64(f), nil
+		}
+		return f, nil
+	}
+
+	if cfg.trimSpace {
+		s = strings.TrimSpace(s)
+	}
+	return s, nil
+}
+
+// xlsxTime converts a serial date as used by Excel to time.Time.
+// The result is rounded to milliseconds, which is the precision Excel
+// stores.
+func xlsxTime(serial float64, date1904 bool) time.Time {
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	days := math.Floor(serial)
+	ms := math.Round((serial - days) * 24 * 60 * 60 * 1000)
+	return epoch.AddDate(0, 0, int(days)).Add(time.Duration(ms) * time.Millisecond)
+}
+
+// xlsxMaxColumns is the number of columns of a worksheet, the last
+// column is XFD.
+const xlsxMaxColumns = 16384
+
+// xlsxColumnIndex returns the zero-based column index of a cell
+// reference like "AB12".
+func xlsxColumnIndex(ref string) (int, error) {
+	index := 0
+	i := 0
+	for ; i < len(ref); i++ {
+		c := ref[i]
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		index = index*26 + int(c-'A'+1)
+		if index > xlsxMaxColumns {
+			return 0, fmt.Errorf("dataparse: cell reference %q is beyond the last column XFD", ref)
+		}
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("dataparse: invalid cell reference %q", ref)
+	}
+	return index - 1, nil
+}
+
+// xlsxColumnName returns the column name for a zero-based index.
+func xlsxColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,141 @@
+package dataparse
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom_Xlsx(t *testing.T) {
+	maps, err := From("./testdata/data.xlsx")
+	require.Nil(t, err)
+
+	m := <-maps
+	require.Nil(t, m.Err)
+	assert.Equal(t, int64(1), m.Map.MustGet("id").Data)
+	assert.Equal(t, "Robbie", m.Map.MustGet("first_name").MustString())
+	assert.Equal(t, "rmcffaden0@merriam-webster.com", m.Map.MustGet("email").MustString())
+	assert.Equal(t,
+		time.Date(2023, time.January, 10, 12, 15, 53, 0, time.UTC),
+		m.Map.MustGet("timestamp").MustTime(),
+	)
+	assert.Equal(t, true, m.Map.MustGet("active").Data)
+	assert.Equal(t, 1.5, m.Map.MustGet("score").Data)
+
+	m = <-maps
+	require.Nil(t, m.Err)
+	assert.Equal(t, "Salvin", m.Map.MustGet("last_name").MustString())
+	assert.Equal(t, false, m.Map.MustGet("active").Data)
+	assert.Equal(t, int64(3), m.Map.MustGet("score").Data)
+
+	count := 2
+	for m := range maps {
+		require.Nil(t, m.Err)
+		count++
+	}
+	assert.Equal(t, 10, count)
+}
+
+func TestFromXlsx_Sheet(t *testing.T) {
+	b, err := os.ReadFile("./testdata/data.xlsx")
+	require.Nil(t, err)
+
+	for _, opt := range []FromOption{WithSheet("hosts"), WithSheetIndex(1)} {
+		// bytes.Buffer does not implement io.ReaderAt
+		maps := FromXlsx(bytes.NewBuffer(b), opt)
+
+		m := <-maps
+		require.Nil(t, m.Err)
+		assert.Equal(t, "alpha", m.Map.MustGet("hostname").MustString())
+		assert.Equal(t, net.ParseIP("10.0.0.1"), m.Map.MustGet("ip").MustIP())
+
+		m = <-maps
+		require.Nil(t, m.Err)
+		assert.Equal(t, "beta", m.Map.MustGet("hostname").MustString())
+		assert.Equal(t, net.ParseIP("10.0.0.2"), m.Map.MustGet("ip").MustIP())
+
+		_, ok := <-maps
+		assert.False(t, ok)
+	}
+
+	m := <-FromXlsx(bytes.NewReader(b), WithSheet("missing"))
+	require.NotNil(t, m.Err)
+
+	m = <-FromXlsx(bytes.NewReader(b), WithSheetIndex(2))
+	require.NotNil(t, m.Err)
+}
+
+func TestFromXlsx_AllSheets(t *testing.T) {
+	b, err := os.ReadFile("./testdata/data.xlsx")
+	require.Nil(t, err)
+
+	sheets := map[string]int{}
+	for m := range FromXlsx(bytes.NewReader(b), WithAllSheets()) {
+		require.Nil(t, m.Err)
+		sheets[m.Map.MustGet("_sheet").MustString()]++
+	}
+	assert.Equal(t, map[string]int{"people": 10, "hosts": 2}, sheets)
+}
+
+func TestIsDateFormatCode(t *testing.T) {
+	assert.True(t, isDateFormatCode("yyyy-mm-dd"))
+	assert.True(t, isDateFormatCode("[h]:mm"))
+	assert.True(t, isDateFormatCode(`[Red]hh:mm`))
+	assert.False(t, isDateFormatCode("0.00"))
+	assert.False(t, isDateFormatCode(`[Red]#,##0`))
+	assert.False(t, isDateFormatCode(`"days" 0`))
+	assert.False(t, isDateFormatCode(`0\d`))
+}
+
+func TestXlsxColumn(t *testing.T) {
+	for name, index := range map[string]int{"A": 0, "Z": 25, "AA": 26, "AB": 27, "ZZ": 701, "AAA": 702, "XFD": 16383} {
+		i, err := xlsxColumnIndex(name + "1")
+		require.Nil(t, err)
+		assert.Equal(t, index, i)
+		assert.Equal(t, name, xlsxColumnName(index))
+	}
+
+	for _, ref := range []string{"1", "XFE1", "ZZZZZZZZZZZZZZZ2"} {
+		_, err := xlsxColumnIndex(ref)
+		assert.NotNil(t, err, ref)
+	}
+}
+
+func TestFromXlsx_InvalidCellReference(t *testing.T) {
+	src, err := zip.OpenReader("./testdata/data.xlsx")
+	require.Nil(t, err)
+	defer src.Close()
+
+	// copy the workbook with a cell reference beyond the last column
+	buf := &bytes.Buffer{}
+	dst := zip.NewWriter(buf)
+	for _, f := range src.File {
+		r, err := f.Open()
+		require.Nil(t, err)
+		b, err := io.ReadAll(r)
+		require.Nil(t, err)
+		require.Nil(t, r.Close())
+
+		if f.Name == "xl/worksheets/sheet2.xml" {
+			b = bytes.Replace(b, []byte(`r="B2"`), []byte(`r="ZZZZZZZZZZZZZZZ2"`), 1)
+		}
+		w, err := dst.Create(f.Name)
+		require.Nil(t, err)
+		_, err = w.Write(b)
+		require.Nil(t, err)
+	}
+	require.Nil(t, dst.Close())
+
+	var lastErr error
+	for m := range FromXlsx(bytes.NewReader(buf.Bytes()), WithSheet("hosts")) {
+		lastErr = m.Err
+	}
+	assert.ErrorContains(t, lastErr, "beyond the last column")
+}
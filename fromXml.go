@@ -0,0 +1,207 @@
+package dataparse
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"strings"
+)
+
+// FromXml returns maps parsed from an XML document.
+//
+// Elements are converted into nested maps:
+//   - Attributes are stored with their name prefixed, see
+//     WithXmlAttributePrefix.
+//   - Child elements are stored under their name. Repeated child
+//     elements are stored as a list.
+//   - Elements with only text content are stored as strings. The text
+//     of elements with attributes or children is stored under the text
+//     key, see WithXmlTextKey.
+//
+// Namespaces are dropped from element and attribute names.
+//
+// By default the root element is returned as a single map with the
+// root element name as the only key.
+// With WithXmlPath each element at the path is returned as a map, one
+// element at a time, so memory usage is proportional to the size of
+// a single element instead of the size of the document.
+//
+// FromXml is a wrapper around XmlAll, returning the results in
+// a channel.
+func FromXml(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromXml(cfg))
+}
+
+// FromXmlContext is FromXml with the passed context, see WithContext.
+func FromXmlContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromXml(reader, append(opts, WithContext(ctx))...)
+}
+
+// XmlAll returns an iterator over the maps parsed from an XML document,
+// see FromXml.
+func XmlAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromXml(cfg)
+}
+
+func fromXml(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		r := &xmlReader{
+			cfg:     cfg,
+			decoder: xml.NewDecoder(cfg.reader),
+		}
+		// charsets other than UTF-8 are passed through as is
+		r.decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+
+		// stack holds the names of the open elements
+		stack := []string{}
+		index := 0
+		for {
+			offset := r.decoder.InputOffset()
+			line, _ := r.decoder.InputPos()
+			token, err := r.decoder.Token()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					yield(nil, NewErrRecord(r.provenance(index, line, offset), err))
+				}
+				return
+			}
+
+			switch typed := token.(type) {
+			case xml.StartElement:
+				stack = append(stack, typed.Name.Local)
+				if !r.matches(stack) {
+					continue
+				}
+
+				p := r.provenance(index, line, offset)
+				index++
+
+				value, err := r.element(typed)
+				if err != nil {
+					// the decoder cannot continue after syntax errors
+					yield(nil, NewErrRecord(p, err))
+					return
+				}
+				stack = stack[:len(stack)-1]
+
+				if len(cfg.xmlPath) == 0 {
+					value = map[string]any{typed.Name.Local: value}
+				}
+
+				m, err := NewMap(value)
+				if err != nil {
+					if !cfg.recordError(yield, NewErrRecord(p,
+						fmt.Errorf("dataparse: element %q has no attributes or children: %w", typed.Name.Local, err))) {
+						return
+					}
+					continue
+				}
+				m.Provenance = p
+				if !yield(m, nil) {
+					return
+				}
+			case xml.EndElement:
+				stack = stack[:len(stack)-1]
+			}
+		}
+	})
+}
+
+// xmlReader converts the elements of an XML document into maps.
+type xmlReader struct {
+	cfg     *fromConfig
+	decoder *xml.Decoder
+}
+
+func (r *xmlReader) provenance(index, line int, offset int64) Provenance {
+	return Provenance{
+		Source: r.cfg.source,
+		Member: r.cfg.member,
+		Index:  index,
+		Line:   line,
+		Offset: offset,
+	}
+}
+
+// matches returns true if the open elements match the configured path.
+// Without a path only the root element matches.
+func (r *xmlReader) matches(stack []string) bool {
+	if len(r.cfg.xmlPath) == 0 {
+		return len(stack) == 1
+	}
+
+	if len(stack) != len(r.cfg.xmlPath) {
+		return false
+	}
+	for i, pattern := range r.cfg.xmlPath {
+		if ok, _ := path.Match(pattern, stack[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// element consumes the tokens up to the end of the element and returns
+// its value.
+func (r *xmlReader) element(start xml.StartElement) (any, error) {
+	m := map[string]any{}
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		m[r.cfg.xmlAttrPrefix+attr.Name.Local] = attr.Value
+	}
+
+	text := strings.Builder{}
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch typed := token.(type) {
+		case xml.StartElement:
+			child, err := r.element(typed)
+			if err != nil {
+				return nil, err
+			}
+			addXmlChild(m, typed.Name.Local, child)
+		case xml.CharData:
+			text.Write(typed)
+		case xml.EndElement:
+			trimmed := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return trimmed, nil
+			}
+			if trimmed != "" {
+				m[r.cfg.xmlTextKey] = trimmed
+			}
+			return m, nil
+		}
+	}
+}
+
+// addXmlChild stores the value of a child element in m, converting
+// the entry into a list if the element is repeated.
+func addXmlChild(m map[string]any, name string, value any) {
+	existing, ok := m[name]
+	if !ok {
+		m[name] = value
+		return
+	}
+	if list, ok := existing.([]any); ok {
+		m[name] = append(list, value)
+		return
+	}
+	m[name] = []any{existing, value}
+}
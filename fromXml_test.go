@@ -0,0 +1,138 @@
+package dataparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const xmlCatalog = `<?xml version="1.0" encoding="UTF-8"?>
+<catalog xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <book id="bk101" lang="en">
+    <dc:author>Gambardella, Matthew</dc:author>
+    <title>XML Developer's Guide</title>
+    <price currency="USD">44.95</price>
+    <tag>xml</tag>
+    <tag>guide</tag>
+  </book>
+  <book id="bk102">
+    <author>Ralls, Kim</author>
+    <title>Midnight Rain</title>
+    <price>5.95</price>
+  </book>
+</catalog>
+`
+
+func TestXmlAll(t *testing.T) {
+	m, err := single(XmlAll(strings.NewReader(xmlCatalog)))
+	require.Nil(t, err)
+
+	books, err := m.MustGet("catalog.book").List()
+	require.Nil(t, err)
+	require.Len(t, books, 2)
+
+	book, err := books[0].Map()
+	require.Nil(t, err)
+	assert.Equal(t, "bk101", book.MustGet("@id").MustString())
+	assert.Equal(t, "Gambardella, Matthew", book.MustGet("author").MustString())
+	assert.Equal(t, 44.95, book.MustGet("price.#text").MustFloat64())
+	assert.Equal(t, "USD", book.MustGet("price.@currency").MustString())
+	assert.Equal(t, []string{"xml", "guide"}, book.MustGet("tag").MustListString(","))
+}
+
+func TestXmlAll_Path(t *testing.T) {
+	ids := []string{}
+	lines := []int{}
+	for m, err := range XmlAll(strings.NewReader(xmlCatalog), WithXmlPath("catalog/book")) {
+		require.Nil(t, err)
+		ids = append(ids, m.MustGet("@id").MustString())
+		lines = append(lines, m.Provenance.Line)
+	}
+	assert.Equal(t, []string{"bk101", "bk102"}, ids)
+	assert.Equal(t, []int{3, 10}, lines)
+
+	m, err := single(XmlAll(strings.NewReader(xmlCatalog), WithXmlPath("/*/book/")))
+	require.Nil(t, err)
+	assert.Equal(t, "bk101", m.MustGet("@id").MustString())
+}
+
+func TestXmlAll_Options(t *testing.T) {
+	m, err := single(XmlAll(strings.NewReader(xmlCatalog),
+		WithXmlPath("catalog/book"),
+		WithXmlAttributePrefix("attr_"),
+		WithXmlTextKey("value"),
+	))
+	require.Nil(t, err)
+	assert.Equal(t, "bk101", m.MustGet("attr_id").MustString())
+	assert.Equal(t, "USD", m.MustGet("price.attr_currency").MustString())
+	assert.Equal(t, "44.95", m.MustGet("price.value").MustString())
+}
+
+// endlessXmlFeed is a reader producing an XML feed that never ends.
+type endlessXmlFeed struct {
+	i   int
+	buf []byte
+}
+
+func (r *endlessXmlFeed) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.i == 0 {
+			r.buf = append(r.buf, "<feed>"...)
+		}
+		r.buf = fmt.Appendf(r.buf, `<entry id="%d"><title>entry</title></entry>`, r.i)
+		r.i++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestXmlAll_Stream(t *testing.T) {
+	count := 0
+	for m, err := range XmlAll(&endlessXmlFeed{}, WithXmlPath("feed/entry")) {
+		require.Nil(t, err)
+		assert.Equal(t, count, m.MustGet("@id").MustInt())
+		count++
+		if count == 1000 {
+			break
+		}
+	}
+	assert.Equal(t, 1000, count)
+}
+
+func TestXmlAll_Errors(t *testing.T) {
+	input := `<feed><entry id="1"/><entry>text only</entry><entry id="3"/><entry id="4">`
+
+	ids := []int{}
+	errs := []error{}
+	for m, err := range XmlAll(strings.NewReader(input), WithXmlPath("feed/entry"), WithErrorPolicy(ErrorPolicySkip)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ids = append(ids, m.MustGet("@id").MustInt())
+	}
+	assert.Equal(t, []int{1, 3}, ids)
+	require.Len(t, errs, 2)
+	assert.ErrorContains(t, errs[0], "record 1")
+	assert.ErrorContains(t, errs[1], "unexpected EOF")
+}
+
+func TestFrom_Xml(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.xml")
+	require.Nil(t, os.WriteFile(path, []byte(xmlCatalog), 0o644))
+
+	m, err := FromSingle(path, WithXmlPath("catalog/book"))
+	require.Nil(t, err)
+	assert.Equal(t, "bk101", m.MustGet("@id").MustString())
+	assert.Equal(t, path, m.Provenance.Source)
+
+	m, err = single(ReaderAll(strings.NewReader(xmlCatalog), WithXmlPath("catalog/book")))
+	require.Nil(t, err)
+	assert.Equal(t, "bk101", m.MustGet("@id").MustString())
+}
@@ -0,0 +1,109 @@
+package dataparse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// FromYaml returns maps parsed from a YAML stream.
+//
+// Each document separated by "---" is returned as a map. Documents
+// consisting of a list are returned as one map per element, like
+// arrays in FromJson.
+//
+// Mappings with non-string keys are returned with the keys as decoded,
+// e.g. as int.
+//
+// FromYaml is a wrapper around YamlAll, returning the results in
+// a channel.
+func FromYaml(reader io.Reader, opts ...FromOption) chan FromResult {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return cfg.channel(fromYaml(cfg))
+}
+
+// FromYamlContext is FromYaml with the passed context, see WithContext.
+func FromYamlContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromYaml(reader, append(opts, WithContext(ctx))...)
+}
+
+// YamlAll returns an iterator over the maps parsed from a YAML stream,
+// see FromYaml.
+func YamlAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromYaml(cfg)
+}
+
+func fromYaml(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		decoder := yaml.NewDecoder(cfg.reader)
+		index := 0
+
+		record := func(node *yaml.Node) bool {
+			p := Provenance{
+				Source: cfg.source,
+				Member: cfg.member,
+				Index:  index,
+				Line:   node.Line,
+			}
+			index++
+
+			var value any
+			if err := node.Decode(&value); err != nil {
+				return cfg.recordError(yield, NewErrRecord(p, err))
+			}
+
+			m, err := NewMap(value)
+			if err != nil {
+				return cfg.recordError(yield,
+					NewErrRecord(p, fmt.Errorf("dataparse: unhandled type %T: %w", value, err)))
+			}
+			m.Provenance = p
+			return yield(m, nil)
+		}
+
+		for {
+			var doc yaml.Node
+			if err := decoder.Decode(&doc); err != nil {
+				if !errors.Is(err, io.EOF) {
+					// the decoder cannot continue after syntax errors
+					yield(nil, NewErrRecord(Provenance{
+						Source: cfg.source,
+						Member: cfg.member,
+						Index:  index,
+					}, err))
+				}
+				return
+			}
+
+			if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+				continue
+			}
+
+			node := doc.Content[0]
+			if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+				// empty document, e.g. after a trailing separator
+				continue
+			}
+
+			if node.Kind != yaml.SequenceNode {
+				if !record(node) {
+					return
+				}
+				continue
+			}
+
+			for _, elem := range node.Content {
+				if !record(elem) {
+					return
+				}
+			}
+		}
+	})
+}
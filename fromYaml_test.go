@@ -0,0 +1,79 @@
+package dataparse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrom_Yaml(t *testing.T) {
+	ids := []int{}
+	lines := []int{}
+	for m, err := range All("./testdata/data.yaml") {
+		require.Nil(t, err)
+		ids = append(ids, m.MustGet("id").MustInt())
+		lines = append(lines, m.Provenance.Line)
+		assert.Equal(t, "./testdata/data.yaml", m.Provenance.Source)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ids)
+	assert.Equal(t, []int{2, 9, 16, 23, 30, 38, 45, 52, 59, 66}, lines)
+}
+
+func TestYamlAll_Documents(t *testing.T) {
+	input := `
+id: 1
+nested:
+  key: value
+---
+- id: 2
+- id: 3
+---
+---
+id: 4
+`
+
+	ids := []int{}
+	indexes := []int{}
+	for m, err := range YamlAll(strings.NewReader(input)) {
+		require.Nil(t, err)
+		ids = append(ids, m.MustGet("id").MustInt())
+		indexes = append(indexes, m.Provenance.Index)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4}, ids)
+	assert.Equal(t, []int{0, 1, 2, 3}, indexes)
+
+	m, err := single(YamlAll(strings.NewReader(input)))
+	require.Nil(t, err)
+	assert.Equal(t, "value", m.MustGet("nested.key").MustString())
+}
+
+func TestYamlAll_NonStringKeys(t *testing.T) {
+	input := `
+1: one
+true: yes
+name: two
+`
+
+	m, err := single(YamlAll(strings.NewReader(input)))
+	require.Nil(t, err)
+	assert.Equal(t, "one", m.MustGet(1).MustString())
+	assert.Equal(t, "two", m.MustGet("name").MustString())
+	assert.Equal(t, "yes", m.MustGet(true).MustString())
+}
+
+func TestYamlAll_Errors(t *testing.T) {
+	input := `
+- id: 1
+- just a scalar
+- id: 3
+---
+id: [
+`
+
+	ids, errs := collect(t, YamlAll(strings.NewReader(input), WithErrorPolicy(ErrorPolicySkip)))
+	assert.Equal(t, []int{1, 3}, ids)
+	require.Len(t, errs, 2)
+	assert.ErrorContains(t, errs[0], "record 1 line 3")
+}
@@ -1,29 +1,90 @@
 package dataparse
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"iter"
+	"regexp"
 	"slices"
+	"strings"
 )
 
 type fromConfig struct {
+	ctx         context.Context
 	channelSize int
 
-	separator string
-	trimSpace bool
-	headers   []string
+	separator   string
+	kvDelimiter string
+	trimSpace   bool
+	headers     []string
 
+	columns []Column
+
+	pattern *regexp.Regexp
+
+	jsonPath []string
+
+	xmlPath       []string
+	xmlAttrPrefix string
+	xmlTextKey    string
+
+	projection []string
+
+	avroMaxBlockSize int64
+
+	schemaExamples         int
+	schemaCardinalityLimit int
+
+	tableIndex   int
+	tableId      string
+	tableCaption string
+
+	sheetName  string
+	sheetIndex int
+	allSheets  bool
+	sheetKey   string
+
+	errorPolicy ErrorPolicy
+	maxErrors   int
+	errorCount  int
+
+	include []string
+	exclude []string
+
+	concurrency int
+
+	format  string
+	source  string
+	member  string
 	reader  io.Reader
 	closers []func() error
+	// interrupts close the sources of reader when the context is
+	// cancelled. They must be safe to call concurrently with reads,
+	// like closing an *os.File.
+	interrupts []func() error
 }
 
 func newFromConfig(opts ...FromOption) *fromConfig {
 	cfg := &fromConfig{
+		ctx:         context.Background(),
 		channelSize: 100,
 		separator:   ",",
+		kvDelimiter: "=",
 		trimSpace:   true,
 		headers:     []string{},
+		sheetKey:    "_sheet",
+		concurrency: 1,
 		closers:     []func() error{},
+
+		xmlAttrPrefix: "@",
+		xmlTextKey:    "#text",
+
+		avroMaxBlockSize: 256 << 20,
+
+		schemaExamples:         3,
+		schemaCardinalityLimit: 1000,
 	}
 
 	for _, opt := range opts {
@@ -35,8 +96,7 @@ func newFromConfig(opts ...FromOption) *fromConfig {
 
 func (cfg fromConfig) Close() error {
 	var retErr error
-	slices.Reverse(cfg.closers)
-	for _, closer := range cfg.closers {
+	for _, closer := range slices.Backward(cfg.closers) {
 		if err := closer(); err != nil {
 			retErr = errors.Join(retErr, err)
 		}
@@ -44,8 +104,159 @@ func (cfg fromConfig) Close() error {
 	return retErr
 }
 
+// seq returns an iterator over the maps yielded by fn.
+//
+// Once fn returns, either because the input is exhausted or because
+// the consumer stopped iterating, the readers are closed.
+// When the context is cancelled the sources are interrupted to unblock
+// pending reads, yield returns false and fn is expected to return.
+func (cfg *fromConfig) seq(fn func(yield func(*Map, error) bool)) iter.Seq2[*Map, error] {
+	return func(yield func(*Map, error) bool) {
+		stopInterrupt := context.AfterFunc(cfg.ctx, cfg.interrupt)
+
+		stopped := false
+		defer func() {
+			stopInterrupt()
+			if err := cfg.Close(); err != nil && !stopped && cfg.ctx.Err() == nil {
+				yield(nil, fmt.Errorf("dataparse: error closing reader: %w", err))
+			}
+		}()
+
+		fn(func(m *Map, err error) bool {
+			if cfg.ctx.Err() != nil || !yield(m, err) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// interrupt calls the interrupts to unblock reads from the sources.
+// Decompressors and other readers are only closed after reading
+// stopped as they are not safe to close while they are being read.
+func (cfg *fromConfig) interrupt() {
+	for _, interrupt := range cfg.interrupts {
+		interrupt() //nolint:errcheck
+	}
+}
+
+// channel ranges over seq in a goroutine and returns the channel the
+// results are written to.
+//
+// The channel is closed once seq is exhausted or the context is
+// cancelled.
+func (cfg *fromConfig) channel(seq iter.Seq2[*Map, error]) chan FromResult {
+	ch := make(chan FromResult, cfg.channelSize)
+
+	go func() {
+		defer close(ch)
+		for m, err := range seq {
+			if cfg.ctx.Err() != nil {
+				return
+			}
+			select {
+			case ch <- FromResult{Map: m, Err: err}:
+			case <-cfg.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// recordError yields err for a malformed record and returns true if
+// reading should continue according to the error policy.
+func (cfg *fromConfig) recordError(yield func(*Map, error) bool, err error) bool {
+	if !yield(nil, err) || cfg.errorPolicy == ErrorPolicyStop {
+		return false
+	}
+
+	cfg.errorCount++
+	if cfg.maxErrors > 0 && cfg.errorCount >= cfg.maxErrors {
+		yield(nil, fmt.Errorf("%w: %d malformed records", ErrTooManyErrors, cfg.errorCount))
+		return false
+	}
+	return true
+}
+
 type FromOption func(*fromConfig)
 
+// ErrorPolicy defines how readers handle malformed records.
+type ErrorPolicy int
+
+const (
+	// ErrorPolicyStop reports the first malformed record and stops
+	// reading.
+	ErrorPolicyStop ErrorPolicy = iota
+	// ErrorPolicySkip reports malformed records and continues reading
+	// with the next record.
+	ErrorPolicySkip
+)
+
+// WithErrorPolicy defines how readers handle malformed records.
+//
+// With ErrorPolicySkip readers resynchronize after malformed records:
+//   - CSV continues with the next record, e.g. after records with the
+//     wrong number of fields. After a quoted field that is not
+//     terminated properly CSV continues with the line after the start
+//     of the field.
+//   - JSON streams continue after the next newline following a syntax
+//     error. This only applies to top-level documents like in NDJSON,
+//     syntax errors in arrays still stop reading.
+//
+// Defaults to ErrorPolicyStop.
+func WithErrorPolicy(policy ErrorPolicy) FromOption {
+	return func(opt *fromConfig) {
+		opt.errorPolicy = policy
+	}
+}
+
+// WithMaxErrors defines the number of malformed records after which
+// reading is aborted with ErrTooManyErrors when using ErrorPolicySkip.
+// Defaults to 0, which does not limit the number of errors.
+func WithMaxErrors(n int) FromOption {
+	return func(opt *fromConfig) {
+		opt.maxErrors = n
+	}
+}
+
+// WithContext defines the context for functions returning channels.
+// When the context is cancelled reading stops, all readers are closed
+// and the channel is closed.
+// Defaults to context.Background().
+func WithContext(ctx context.Context) FromOption {
+	return func(opt *fromConfig) {
+		opt.ctx = ctx
+	}
+}
+
+// WithFormat defines the name of the format to read the input as,
+// overriding the file extension. See RegisterFormat for the names of
+// the formats.
+//
+// If the format is FormatAuto the compression and format are detected
+// from the content of the input, ignoring the file extension.
+//
+// Defaults to the format matching the file extension, or FormatAuto if
+// the extension does not match a format.
+func WithFormat(name string) FromOption {
+	return func(opt *fromConfig) {
+		opt.format = name
+	}
+}
+
+// WithSource defines the name of the input recorded in the provenance
+// of maps and errors.
+// Functions reading files always record the path of the file instead.
+// Defaults to an empty string.
+func WithSource(name string) FromOption {
+	return func(opt *fromConfig) {
+		opt.source = name
+	}
+}
+
 // WithChannelSize defines the buffer size of channels for functions
 // returning channels.
 // Defaults to 100.
@@ -65,6 +276,15 @@ func WithSeparator(sep string) FromOption {
 	}
 }
 
+// WithKVDelimiter defines the delimiter between keys and values in
+// FromKVString, e.g. ":" for input like HTTP headers.
+// Defaults to "=".
+func WithKVDelimiter(delim string) FromOption {
+	return func(opt *fromConfig) {
+		opt.kvDelimiter = delim
+	}
+}
+
 // WithTrimSpace defines whether values are trimmed when parsing input.
 // Defaults to true.
 // This does not apply to unmarshalled values like JSON.
@@ -83,3 +303,221 @@ func WithHeaders(headers ...string) FromOption {
 		opt.headers = headers
 	}
 }
+
+// WithColumns defines the columns of fixed-width text, see
+// FromFixedWidth.
+// Defaults to columns inferred from the header line.
+func WithColumns(columns ...Column) FromOption {
+	return func(opt *fromConfig) {
+		opt.columns = columns
+	}
+}
+
+// WithPattern defines the regular expression FromLines matches lines
+// against, see Grok to compile expressions using named patterns and the
+// presets like PatternCombinedLog.
+// The named capture groups of the pattern are the keys of the maps.
+func WithPattern(pattern *regexp.Regexp) FromOption {
+	return func(opt *fromConfig) {
+		opt.pattern = pattern
+	}
+}
+
+// WithSheet defines the name of the sheet to read when reading
+// workbooks like xlsx.
+// Defaults to the first sheet.
+func WithSheet(name string) FromOption {
+	return func(opt *fromConfig) {
+		opt.sheetName = name
+	}
+}
+
+// WithSheetIndex defines the zero-based index of the sheet to read when
+// reading workbooks like xlsx.
+// Defaults to 0.
+func WithSheetIndex(i int) FromOption {
+	return func(opt *fromConfig) {
+		opt.sheetIndex = i
+	}
+}
+
+// WithAllSheets defines that all sheets are read when reading
+// workbooks like xlsx.
+// Each map is tagged with the name of its sheet, see WithSheetKey.
+// Defaults to false.
+func WithAllSheets() FromOption {
+	return func(opt *fromConfig) {
+		opt.allSheets = true
+	}
+}
+
+// WithSheetKey defines the key under which the sheet name is stored in
+// each map when reading all sheets of a workbook.
+// Defaults to "_sheet".
+func WithSheetKey(key string) FromOption {
+	return func(opt *fromConfig) {
+		opt.sheetKey = key
+	}
+}
+
+// WithJsonPath defines the path to an array or object in JSON
+// documents to read maps from, with the keys separated by dots.
+// This allows to stream records from wrapper documents like
+// {"meta": {...}, "data": {"items": [...]}} with the path "data.items".
+// Defaults to the document root.
+func WithJsonPath(path string) FromOption {
+	return func(opt *fromConfig) {
+		opt.jsonPath = nil
+		if path != "" {
+			opt.jsonPath = strings.Split(path, ".")
+		}
+	}
+}
+
+// WithXmlPath defines the path to repeating elements in XML documents
+// to read maps from, with the element names separated by slashes and
+// starting at the root element, e.g. "catalog/book".
+// Names can be patterns as in path.Match, e.g. "feed/*".
+// Defaults to the root element.
+func WithXmlPath(path string) FromOption {
+	return func(opt *fromConfig) {
+		opt.xmlPath = nil
+		if path != "" {
+			opt.xmlPath = strings.Split(strings.Trim(path, "/"), "/")
+		}
+	}
+}
+
+// WithXmlAttributePrefix defines the prefix for the keys of attributes
+// of XML elements.
+// Defaults to "@".
+func WithXmlAttributePrefix(prefix string) FromOption {
+	return func(opt *fromConfig) {
+		opt.xmlAttrPrefix = prefix
+	}
+}
+
+// WithXmlTextKey defines the key for the text content of XML elements
+// with attributes or child elements.
+// Elements with only text content are returned as strings.
+// Defaults to "#text".
+func WithXmlTextKey(key string) FromOption {
+	return func(opt *fromConfig) {
+		opt.xmlTextKey = key
+	}
+}
+
+// WithTableIndex defines the zero-based index of the table to read
+// when reading HTML or Markdown tables.
+// Defaults to 0.
+func WithTableIndex(i int) FromOption {
+	return func(opt *fromConfig) {
+		opt.tableIndex = i
+	}
+}
+
+// WithTableId defines the id of the table to read when reading HTML or
+// Markdown tables, see FromHtmlTable and FromMarkdownTable.
+// Takes precedence over WithTableIndex.
+// Defaults to an empty string.
+func WithTableId(id string) FromOption {
+	return func(opt *fromConfig) {
+		opt.tableId = id
+	}
+}
+
+// WithTableCaption defines the caption of the table to read when
+// reading HTML or Markdown tables, see FromHtmlTable and
+// FromMarkdownTable. Captions are compared case-insensitively.
+// Takes precedence over WithTableIndex.
+// Defaults to an empty string.
+func WithTableCaption(caption string) FromOption {
+	return func(opt *fromConfig) {
+		opt.tableCaption = caption
+	}
+}
+
+// WithProjection defines the top-level columns or fields to read from
+// columnar and schema-based formats like Parquet and Avro.
+// Only the projected columns are decoded, all other columns are
+// skipped.
+// Defaults to all columns.
+func WithProjection(columns ...string) FromOption {
+	return func(opt *fromConfig) {
+		opt.projection = columns
+	}
+}
+
+// WithAvroMaxBlockSize defines the maximum size in bytes of a block
+// of an Avro file before decompression.
+// Larger blocks are reported as an error instead of allocating memory
+// for the size read from a corrupt file.
+// Defaults to 256 MiB.
+func WithAvroMaxBlockSize(size int64) FromOption {
+	return func(opt *fromConfig) {
+		opt.avroMaxBlockSize = size
+	}
+}
+
+// WithSchemaExamples defines the number of distinct example values
+// InferSchema records per field.
+// Defaults to 3.
+func WithSchemaExamples(n int) FromOption {
+	return func(opt *fromConfig) {
+		opt.schemaExamples = n
+	}
+}
+
+// WithSchemaCardinalityLimit defines the number of distinct values
+// after which InferSchema stops counting the cardinality of a field,
+// which limits the memory used for fields with unique values like IDs.
+// Defaults to 1000.
+func WithSchemaCardinalityLimit(n int) FromOption {
+	return func(opt *fromConfig) {
+		opt.schemaCardinalityLimit = n
+	}
+}
+
+// WithInclude defines glob patterns of archive members to read.
+// Members are read if their path or base name matches any of the
+// patterns, see path.Match for the syntax.
+// Included members with an unknown extension are read with the format
+// detected from their content.
+// Defaults to all members with a known format.
+func WithInclude(patterns ...string) FromOption {
+	return func(opt *fromConfig) {
+		opt.include = patterns
+	}
+}
+
+// WithExclude defines glob patterns of archive members to skip.
+// Members are skipped if their path or base name matches any of the
+// patterns, see path.Match for the syntax.
+// Exclusion takes precedence over WithInclude.
+// Defaults to no patterns.
+func WithExclude(patterns ...string) FromOption {
+	return func(opt *fromConfig) {
+		opt.exclude = patterns
+	}
+}
+
+// withMember defines the archive member recorded in the provenance of
+// maps and errors.
+func withMember(name string) FromOption {
+	return func(opt *fromConfig) {
+		opt.member = name
+	}
+}
+
+// WithConcurrency defines the number of files FromGlob and FromFS read
+// concurrently, which is also the maximum number of open files.
+//
+// With a concurrency of one the files are read one after another in
+// lexical order. With a higher concurrency the records of the files are
+// interleaved in the order they are read.
+// Defaults to 1.
+func WithConcurrency(n int) FromOption {
+	return func(opt *fromConfig) {
+		opt.concurrency = max(n, 1)
+	}
+}
@@ -0,0 +1,258 @@
+package dataparse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// FromGlob returns maps parsed from all files matching the pattern as
+// a single stream, see filepath.Match for the pattern syntax.
+// If the pattern is a directory all files in the directory with
+// a known format are read.
+//
+// The files are read with From and the path of each file is recorded
+// as the source in the provenance of its maps.
+// By default the files are read one after another in lexical order,
+// see WithConcurrency to read multiple files at once.
+//
+// Errors reading a file are handled according to the error policy,
+// with ErrorPolicySkip the remaining files are read.
+//
+// FromGlob is a wrapper around GlobAll, returning the results in
+// a channel.
+// An error is returned if the pattern is malformed or matches no
+// files, including directories without files with a known format.
+func FromGlob(pattern string, opts ...FromOption) (chan FromResult, error) {
+	cfg := newFromConfig(opts...)
+	seq, err := cfg.glob(osFileSystem, pattern, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.channel(seq), nil
+}
+
+// GlobAll returns an iterator over the maps parsed from all files
+// matching the pattern, see FromGlob.
+// Errors matching the pattern are yielded as the only element.
+func GlobAll(pattern string, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	seq, err := cfg.glob(osFileSystem, pattern, opts...)
+	if err != nil {
+		return errSeq(err)
+	}
+	return seq
+}
+
+// FromFS returns maps parsed from all files in fsys matching the
+// pattern as a single stream, see fs.Glob for the pattern syntax.
+//
+// FromFS behaves like FromGlob with the name of each file in fsys
+// recorded as the source in the provenance of its maps.
+//
+// FromFS is a wrapper around FSAll, returning the results in a channel.
+func FromFS(fsys fs.FS, pattern string, opts ...FromOption) (chan FromResult, error) {
+	cfg := newFromConfig(opts...)
+	seq, err := cfg.glob(fsFileSystem(fsys), pattern, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.channel(seq), nil
+}
+
+// FSAll returns an iterator over the maps parsed from all files in
+// fsys matching the pattern, see FromFS.
+// Errors matching the pattern are yielded as the only element.
+func FSAll(fsys fs.FS, pattern string, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	seq, err := cfg.glob(fsFileSystem(fsys), pattern, opts...)
+	if err != nil {
+		return errSeq(err)
+	}
+	return seq
+}
+
+// fileSystem abstracts the operations to find and open files in the
+// file system of the OS and in an fs.FS.
+type fileSystem struct {
+	glob    func(pattern string) ([]string, error)
+	stat    func(name string) (fs.FileInfo, error)
+	readDir func(name string) ([]fs.DirEntry, error)
+	join    func(elem ...string) string
+	open    func(name string) (io.ReadCloser, error)
+}
+
+var osFileSystem = fileSystem{
+	glob:    filepath.Glob,
+	stat:    os.Stat,
+	readDir: os.ReadDir,
+	join:    filepath.Join,
+	open: func(name string) (io.ReadCloser, error) {
+		return os.Open(name)
+	},
+}
+
+func fsFileSystem(fsys fs.FS) fileSystem {
+	return fileSystem{
+		glob: func(pattern string) ([]string, error) {
+			return fs.Glob(fsys, pattern)
+		},
+		stat: func(name string) (fs.FileInfo, error) {
+			return fs.Stat(fsys, name)
+		},
+		readDir: func(name string) ([]fs.DirEntry, error) {
+			return fs.ReadDir(fsys, name)
+		},
+		join: path.Join,
+		open: func(name string) (io.ReadCloser, error) {
+			return fsys.Open(name)
+		},
+	}
+}
+
+// match returns the regular files matching the pattern in lexical
+// order.
+// If the pattern is a directory the files in the directory with
+// a known format are returned.
+// An error is returned if no files match.
+func (files fileSystem) match(pattern string) ([]string, error) {
+	names, err := files.matchFiles(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("dataparse: no files match pattern %q", pattern)
+	}
+	return names, nil
+}
+
+func (files fileSystem) matchFiles(pattern string) ([]string, error) {
+	if info, err := files.stat(pattern); err == nil && info.IsDir() {
+		entries, err := files.readDir(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("dataparse: error reading directory: %w", err)
+		}
+
+		names := []string{}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && hasKnownExtension(entry.Name()) {
+				names = append(names, files.join(pattern, entry.Name()))
+			}
+		}
+		return names, nil
+	}
+
+	matches, err := files.glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("dataparse: error matching pattern %q: %w", pattern, err)
+	}
+
+	names := []string{}
+	for _, name := range matches {
+		info, err := files.stat(name)
+		if err != nil {
+			return nil, fmt.Errorf("dataparse: error reading file: %w", err)
+		}
+		if info.Mode().IsRegular() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// glob returns an iterator over the maps parsed from the files matching
+// the pattern.
+func (cfg *fromConfig) glob(files fileSystem, pattern string, opts ...FromOption) (iter.Seq2[*Map, error], error) {
+	names, err := files.match(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	all := func(ctx context.Context, name string) iter.Seq2[*Map, error] {
+		// opts is shared between goroutines and must not be appended to
+		fileOpts := slices.Concat(opts, []FromOption{WithContext(ctx)})
+		seq, err := openFileFrom(files, newFromConfig(fileOpts...), name, fileOpts...)
+		if err != nil {
+			return errSeq(err)
+		}
+		return seq
+	}
+
+	if cfg.concurrency <= 1 {
+		return cfg.seq(func(yield func(*Map, error) bool) {
+			for _, name := range names {
+				for m, err := range all(cfg.ctx, name) {
+					if err != nil {
+						if !cfg.forwardError(yield, err) {
+							return
+						}
+						continue
+					}
+					if !yield(m, nil) {
+						return
+					}
+				}
+			}
+		}), nil
+	}
+
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		ctx, cancel := context.WithCancel(cfg.ctx)
+		defer cancel()
+
+		results := make(chan FromResult)
+		go func() {
+			defer close(results)
+
+			wg := sync.WaitGroup{}
+			defer wg.Wait()
+
+			// limits the number of open files
+			sem := make(chan struct{}, cfg.concurrency)
+			for _, name := range names {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+
+				wg.Go(func() {
+					defer func() { <-sem }()
+					for m, err := range all(ctx, name) {
+						select {
+						case results <- FromResult{Map: m, Err: err}:
+						case <-ctx.Done():
+							return
+						}
+					}
+				})
+			}
+		}()
+
+		// wait for all files to be closed before returning
+		defer func() {
+			cancel()
+			for range results {
+			}
+		}()
+
+		for result := range results {
+			if result.Err != nil {
+				if !cfg.forwardError(yield, result.Err) {
+					return
+				}
+				continue
+			}
+			if !yield(result.Map, nil) {
+				return
+			}
+		}
+	}), nil
+}
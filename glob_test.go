@@ -0,0 +1,174 @@
+package dataparse
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobAll(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"2026-01/events.csv":  "id\n1\n2\n",
+		"2026-02/events.csv":  "id\n3\n",
+		"2026-02/other.csv":   "id\n4\n",
+		"2025-12/events.json": `{"id": 5}`,
+	} {
+		path := filepath.Join(dir, name)
+		require.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.Nil(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+
+	sources := []string{}
+	ids := []int{}
+	for m, err := range GlobAll(filepath.Join(dir, "2026-*", "events.csv")) {
+		require.Nil(t, err)
+		ids = append(ids, m.MustGet("id").MustInt())
+		sources = append(sources, m.Provenance.Source)
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "2026-01", "events.csv"),
+		filepath.Join(dir, "2026-01", "events.csv"),
+		filepath.Join(dir, "2026-02", "events.csv"),
+	}, sources)
+
+	// directories are expanded to the files with a known format
+	ids, errs := collect(t, GlobAll(filepath.Join(dir, "2026-02")))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{3, 4}, ids)
+
+	// the path of each file is recorded regardless of WithSource
+	sources = []string{}
+	for m, err := range GlobAll(filepath.Join(dir, "2026-02", "*.csv"), WithSource("ignored")) {
+		require.Nil(t, err)
+		sources = append(sources, m.Provenance.Source)
+	}
+	assert.Equal(t, []string{
+		filepath.Join(dir, "2026-02", "events.csv"),
+		filepath.Join(dir, "2026-02", "other.csv"),
+	}, sources)
+
+	_, err := FromGlob(filepath.Join(dir, "2027-*", "*.csv"))
+	assert.ErrorContains(t, err, "no files match pattern")
+
+	// directories without files with a known format are treated the
+	// same as patterns without matches
+	empty := filepath.Join(dir, "empty")
+	require.Nil(t, os.MkdirAll(empty, 0o755))
+	require.Nil(t, os.WriteFile(filepath.Join(empty, "notes.unknown"), []byte("x"), 0o644))
+	_, err = FromGlob(empty)
+	assert.ErrorContains(t, err, "no files match pattern")
+
+	_, err = FromGlob(filepath.Join(dir, "["))
+	assert.ErrorIs(t, err, filepath.ErrBadPattern)
+}
+
+func TestFSAll(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.csv":      {Data: []byte("id\n1\n2\n")},
+		"b.ndjson":   {Data: []byte(`{"id": 3}` + "\n" + `{"id": 4}` + "\n")},
+		"c.csv":      {Data: []byte("id\n5\n")},
+		"readme.txt": {Data: []byte("not data")},
+	}
+
+	ids, errs := collect(t, FSAll(fsys, "*.*sv"))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{1, 2, 5}, ids)
+
+	ch, err := FromFS(fsys, ".")
+	require.Nil(t, err)
+	sources := []string{}
+	for result := range ch {
+		require.Nil(t, result.Err)
+		sources = append(sources, result.Map.Provenance.Source)
+	}
+	assert.Equal(t, []string{"a.csv", "a.csv", "b.ndjson", "b.ndjson", "c.csv"}, sources)
+}
+
+func TestFSAll_ErrorPolicy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.csv": {Data: []byte("id\n1\n")},
+		"b.csv": {Data: []byte("id\n2,3\n")},
+		"c.csv": {Data: []byte("id\n4\n")},
+	}
+
+	ids, errs := collect(t, FSAll(fsys, "*.csv"))
+	assert.Equal(t, []int{1}, ids)
+	assert.Len(t, errs, 1)
+
+	ids, errs = collect(t, FSAll(fsys, "*.csv", WithErrorPolicy(ErrorPolicySkip)))
+	assert.Equal(t, []int{1, 4}, ids)
+	require.Len(t, errs, 1)
+
+	var errRecord ErrRecord
+	require.True(t, errors.As(errs[0], &errRecord))
+	assert.Equal(t, "b.csv", errRecord.Source)
+}
+
+// countingFS records the maximum number of files open at once.
+type countingFS struct {
+	fstest.MapFS
+
+	mu      sync.Mutex
+	open    int
+	maxOpen int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	f, err := c.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.open++
+	c.maxOpen = max(c.maxOpen, c.open)
+	return &countingFile{File: f, fs: c}, nil
+}
+
+type countingFile struct {
+	fs.File
+	fs *countingFS
+}
+
+func (c *countingFile) Close() error {
+	c.fs.mu.Lock()
+	defer c.fs.mu.Unlock()
+	c.fs.open--
+	return c.File.Close()
+}
+
+func TestFSAll_Concurrency(t *testing.T) {
+	mapFS := fstest.MapFS{}
+	expected := []int{}
+	for i := range 20 {
+		mapFS[strconv.Itoa(i)+".csv"] = &fstest.MapFile{
+			Data: []byte("id\n" + strconv.Itoa(i*2) + "\n" + strconv.Itoa(i*2+1) + "\n"),
+		}
+		expected = append(expected, i*2, i*2+1)
+	}
+
+	fsys := &countingFS{MapFS: mapFS}
+	ids, errs := collect(t, FSAll(fsys, "*.csv", WithConcurrency(3)))
+	assert.Empty(t, errs)
+	slices.Sort(ids)
+	assert.Equal(t, expected, ids)
+	assert.LessOrEqual(t, fsys.maxOpen, 3)
+	assert.Equal(t, 0, fsys.open)
+
+	// stopping early closes all files
+	for range FSAll(fsys, "*.csv", WithConcurrency(3)) {
+		break
+	}
+	assert.Equal(t, 0, fsys.open)
+}
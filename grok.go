@@ -0,0 +1,157 @@
+package dataparse
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+)
+
+// grokPatterns are the named patterns available in Grok expressions.
+// The patterns follow the names of the Logstash grok patterns but are
+// adapted to the RE2 syntax of the regexp package.
+var grokPatterns = map[string]string{
+	"USERNAME":     `[a-zA-Z0-9._-]+`,
+	"USER":         `%{USERNAME}`,
+	"INT":          `[+-]?[0-9]+`,
+	"NUMBER":       `[+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)`,
+	"BASE10NUM":    `%{NUMBER}`,
+	"POSINT":       `\b[1-9][0-9]*\b`,
+	"NONNEGINT":    `\b[0-9]+\b`,
+	"WORD":         `\b\w+\b`,
+	"NOTSPACE":     `\S+`,
+	"SPACE":        `\s*`,
+	"DATA":         `.*?`,
+	"GREEDYDATA":   `.*`,
+	"QUOTEDSTRING": `(?:"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')`,
+	"QS":           `%{QUOTEDSTRING}`,
+	"UUID":         `[A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}`,
+
+	"IPV4":     `(?:(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)\.){3}(?:25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)`,
+	"IPV6":     `(?:[0-9A-Fa-f]{0,4}:){2,7}(?:[0-9A-Fa-f]{1,4}|%{IPV4})?(?:%[0-9A-Za-z]+)?`,
+	"IP":       `(?:%{IPV6}|%{IPV4})`,
+	"HOSTNAME": `\b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*\.?`,
+	"IPORHOST": `(?:%{IP}|%{HOSTNAME})`,
+	"HOSTPORT": `%{IPORHOST}:%{POSINT}`,
+	"PATH":     `(?:/[^\s]*)+`,
+	"URI":      `[A-Za-z][A-Za-z0-9+.-]*://\S+`,
+
+	"MONTH":             `\b(?:[Jj]an(?:uary)?|[Ff]eb(?:ruary)?|[Mm]ar(?:ch)?|[Aa]pr(?:il)?|[Mm]ay|[Jj]un(?:e)?|[Jj]ul(?:y)?|[Aa]ug(?:ust)?|[Ss]ep(?:tember)?|[Oo]ct(?:ober)?|[Nn]ov(?:ember)?|[Dd]ec(?:ember)?)\b`,
+	"MONTHNUM":          `(?:0?[1-9]|1[0-2])`,
+	"MONTHDAY":          `(?:0[1-9]|[12][0-9]|3[01]|[1-9])`,
+	"DAY":               `\b(?:Mon(?:day)?|Tue(?:sday)?|Wed(?:nesday)?|Thu(?:rsday)?|Fri(?:day)?|Sat(?:urday)?|Sun(?:day)?)\b`,
+	"YEAR":              `(?:\d\d){1,2}`,
+	"HOUR":              `(?:2[0123]|[01]?[0-9])`,
+	"MINUTE":            `[0-5][0-9]`,
+	"SECOND":            `(?:[0-5]?[0-9]|60)(?:[:.,][0-9]+)?`,
+	"TIME":              `%{HOUR}:%{MINUTE}(?::%{SECOND})?`,
+	"ISO8601_TIMEZONE":  `(?:Z|[+-]%{HOUR}(?::?%{MINUTE}))`,
+	"TIMESTAMP_ISO8601": `%{YEAR}-%{MONTHNUM}-%{MONTHDAY}[T ]%{HOUR}:?%{MINUTE}(?::?%{SECOND})?%{ISO8601_TIMEZONE}?`,
+	"HTTPDATE":          `%{MONTHDAY}/%{MONTH}/%{YEAR}:%{TIME} %{INT}`,
+	"SYSLOGTIMESTAMP":   `%{MONTH} +%{MONTHDAY} %{TIME}`,
+
+	"LOGLEVEL": `(?:[Aa]lert|ALERT|[Tt]race|TRACE|[Dd]ebug|DEBUG|[Nn]otice|NOTICE|[Ii]nfo|INFO|[Ww]arn(?:ing)?|WARN(?:ING)?|[Ee]rr(?:or)?|ERR(?:OR)?|[Cc]rit(?:ical)?|CRIT(?:ICAL)?|[Ff]atal|FATAL|[Ss]evere|SEVERE|[Ee]merg(?:ency)?|EMERG(?:ENCY)?)`,
+
+	"PROG":       `[\x21-\x5a\x5c\x5e-\x7e]+?`,
+	"SYSLOGPROG": `%{PROG:program}(?:\[%{POSINT:pid}\])?`,
+
+	"COMMONAPACHELOG":   `%{IPORHOST:clientip} %{NOTSPACE:ident} %{NOTSPACE:auth} \[%{HTTPDATE:timestamp}\] "(?:%{WORD:verb} %{NOTSPACE:request}(?: HTTP/%{NUMBER:httpversion})?|%{DATA:rawrequest})" %{INT:response} (?:%{INT:bytes}|-)`,
+	"COMBINEDAPACHELOG": `%{COMMONAPACHELOG} "%{DATA:referrer}" "%{DATA:agent}"`,
+	"SYSLOG3164":        `(?:<%{NONNEGINT:priority}>)?%{SYSLOGTIMESTAMP:timestamp} %{IPORHOST:hostname} %{SYSLOGPROG}: %{GREEDYDATA:message}`,
+	"SYSLOG5424":        `<%{NONNEGINT:priority}>%{NONNEGINT:version} (?:-|%{TIMESTAMP_ISO8601:timestamp}) (?:-|%{NOTSPACE:hostname}) (?:-|%{NOTSPACE:app_name}) (?:-|%{NOTSPACE:proc_id}) (?:-|%{NOTSPACE:msg_id}) (?:-|(?P<structured_data>(?:\[(?:[^\]\\]|\\.)*\])+))(?: %{GREEDYDATA:message})?`,
+}
+
+// Presets for common log formats to use with WithPattern.
+var (
+	// PatternCommonLog matches lines in the Common Log Format of web
+	// servers.
+	PatternCommonLog = MustGrok(`^%{COMMONAPACHELOG}$`)
+	// PatternCombinedLog matches lines in the Combined Log Format of
+	// web servers, which is the default format of nginx access logs.
+	PatternCombinedLog = MustGrok(`^%{COMBINEDAPACHELOG}$`)
+	// PatternSyslog3164 matches BSD syslog lines as defined in RFC
+	// 3164.
+	PatternSyslog3164 = MustGrok(`^%{SYSLOG3164}$`)
+	// PatternSyslog5424 matches syslog lines as defined in RFC 5424.
+	PatternSyslog5424 = MustGrok(`^%{SYSLOG5424}$`)
+)
+
+// grokReference matches references like %{NAME} and %{NAME:field} in
+// Grok expressions.
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::(\w+))?(?::\w+)?\}`)
+
+// RegisterGrokPattern registers a named pattern to use in Grok
+// expressions. Registering a name again replaces the previous pattern.
+//
+// RegisterGrokPattern is not safe for concurrent use and is intended to
+// be called from init functions.
+func RegisterGrokPattern(name, pattern string) {
+	grokPatterns[name] = pattern
+}
+
+// Grok compiles a Grok expression into a regular expression.
+//
+// References to named patterns like %{IP} are replaced with the
+// pattern. References with a field name like %{IP:client} are replaced
+// with a capture group of that name, which FromLines uses as key.
+// A type suffix like %{NUMBER:bytes:int} is accepted and ignored, as
+// values are converted when they are retrieved.
+//
+// Field names must be valid capture group names of the regexp package.
+func Grok(expr string) (*regexp.Regexp, error) {
+	expanded, err := expandGrok(expr, 0)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("dataparse: error compiling grok expression %q: %w", expr, err)
+	}
+	return re, nil
+}
+
+// MustGrok is Grok but panics on error.
+func MustGrok(expr string) *regexp.Regexp {
+	re, err := Grok(expr)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// maxGrokDepth limits the nesting of pattern references to detect
+// cyclic references.
+const maxGrokDepth = 32
+
+func expandGrok(expr string, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", fmt.Errorf("dataparse: grok patterns nested deeper than %d, cyclic reference?", maxGrokDepth)
+	}
+
+	var expandErr error
+	expanded := grokReference.ReplaceAllStringFunc(expr, func(ref string) string {
+		match := grokReference.FindStringSubmatch(ref)
+		name, field := match[1], match[2]
+
+		pattern, ok := grokPatterns[name]
+		if !ok {
+			expandErr = cmp.Or(expandErr, fmt.Errorf("dataparse: unknown grok pattern %q", name))
+			return ref
+		}
+
+		pattern, err := expandGrok(pattern, depth+1)
+		if err != nil {
+			expandErr = cmp.Or(expandErr, err)
+			return ref
+		}
+
+		if field != "" {
+			return "(?P<" + field + ">" + pattern + ")"
+		}
+		return "(?:" + pattern + ")"
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
@@ -0,0 +1,53 @@
+package dataparse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrok(t *testing.T) {
+	re, err := Grok(`%{IP:client} %{WORD:method} %{NUMBER:bytes:int} %{QUOTEDSTRING:msg}`)
+	require.Nil(t, err)
+
+	match := re.FindStringSubmatch(`10.0.0.1 GET 512 "hello \"world\""`)
+	require.NotNil(t, match)
+	assert.Equal(t, "10.0.0.1", match[re.SubexpIndex("client")])
+	assert.Equal(t, "GET", match[re.SubexpIndex("method")])
+	assert.Equal(t, "512", match[re.SubexpIndex("bytes")])
+	assert.Equal(t, `"hello \"world\""`, match[re.SubexpIndex("msg")])
+}
+
+func TestGrok_Patterns(t *testing.T) {
+	for name, valid := range map[string][]string{
+		"TIMESTAMP_ISO8601": {"2026-01-02T03:04:05Z", "2026-01-02 03:04:05.123+01:00"},
+		"IP":                {"192.168.0.1", "::1", "fe80::1ff:fe23:4567:890a"},
+		"HTTPDATE":          {"10/Oct/2000:13:55:36 -0700"},
+		"SYSLOGTIMESTAMP":   {"Oct  1 13:55:36", "Oct 11 13:55:36"},
+		"LOGLEVEL":          {"INFO", "warning", "Error"},
+		"UUID":              {"123e4567-e89b-12d3-a456-426614174000"},
+	} {
+		re := MustGrok(`^%{` + name + `}$`)
+		for _, input := range valid {
+			assert.True(t, re.MatchString(input), "%s should match %q", name, input)
+		}
+	}
+}
+
+func TestGrok_Errors(t *testing.T) {
+	_, err := Grok(`%{UNKNOWN}`)
+	assert.ErrorContains(t, err, `unknown grok pattern "UNKNOWN"`)
+
+	RegisterGrokPattern("CYCLE_A", `%{CYCLE_B}`)
+	RegisterGrokPattern("CYCLE_B", `%{CYCLE_A}`)
+	defer delete(grokPatterns, "CYCLE_A")
+	defer delete(grokPatterns, "CYCLE_B")
+	_, err = Grok(`%{CYCLE_A}`)
+	assert.ErrorContains(t, err, "cyclic reference")
+
+	RegisterGrokPattern("INVALID", `(`)
+	defer delete(grokPatterns, "INVALID")
+	_, err = Grok(`%{INVALID}`)
+	assert.ErrorContains(t, err, "error compiling")
+}
@@ -0,0 +1,111 @@
+package dataparse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// splitOutsideQuotes splits s at the separators outside of double
+// quotes. sep returns the length of the separator at the start of rest
+// or zero if rest does not start with a separator.
+// Backslashes escape quotes inside quoted strings. Quotes without
+// a closing quote are kept as they are, e.g. in size=5".
+func splitOutsideQuotes(s string, sep func(rest string) int) []string {
+	elems := []string{}
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			if end := closingQuote(s[i+1:]); end >= 0 {
+				i += end + 1
+				continue
+			}
+		}
+		if n := sep(s[i:]); n > 0 {
+			elems = append(elems, s[start:i])
+			i += n - 1
+			start = i + 1
+		}
+	}
+	return append(elems, s[start:])
+}
+
+// closingQuote returns the index of the first double quote in s that
+// is not escaped by a backslash or -1.
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unterminatedQuote returns true if s contains a double quote without
+// a closing quote.
+func unterminatedQuote(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' {
+			end := closingQuote(s[i+1:])
+			if end < 0 {
+				return true
+			}
+			i += end + 1
+		}
+	}
+	return false
+}
+
+// parseKV parses the key/value pairs in s separated by sep, with keys
+// and values separated by delim.
+// Keys without a delimiter have a nil value. Quoted keys and values
+// are unquoted.
+func parseKV(s string, sep func(rest string) int, delim string, trimSpace bool) map[any]any {
+	data := map[any]any{}
+	for _, elem := range splitOutsideQuotes(s, sep) {
+		if strings.TrimSpace(elem) == "" {
+			continue
+		}
+
+		// the key and value are split at the first delimiter outside
+		// of quotes
+		split := splitOutsideQuotes(elem, stringSeparator(delim))
+		key := unquoteKV(strings.TrimSpace(split[0]))
+
+		var value any
+		if len(split) > 1 {
+			raw := strings.Join(split[1:], delim)
+			if trimSpace {
+				raw = strings.TrimSpace(raw)
+			}
+			value = unquoteKV(raw)
+		}
+		data[key] = value
+	}
+	return data
+}
+
+// unquoteKV removes surrounding double quotes and decodes escapes.
+// Invalid escapes are kept as they are.
+func unquoteKV(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`)
+}
+
+// stringSeparator returns a separator function for splitOutsideQuotes
+// matching sep.
+func stringSeparator(sep string) func(string) int {
+	return func(rest string) int {
+		if sep != "" && strings.HasPrefix(rest, sep) {
+			return len(sep)
+		}
+		return 0
+	}
+}
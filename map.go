@@ -1,15 +1,17 @@
 package dataparse
 
 import (
-	"compress/gzip"
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/csv"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"os"
-	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -19,7 +21,10 @@ import (
 // It is used to store and retrieve data taken from various sources.
 type Map struct {
 	Data map[any]any
-	cfg  *fromConfig
+	// Provenance describes where the map was read from if it was read
+	// by one of the From functions.
+	Provenance Provenance
+	cfg        *fromConfig
 }
 
 type FromResult struct {
@@ -30,230 +35,428 @@ type FromResult struct {
 // From returns maps parsed from a file.
 //
 // From utilizes other functions for various data types like JSON and
-// CSV.
+// CSV. The format is selected based on the file extension, see
+// RegisterFormat for the handled formats.
+// If the extension is unknown or missing the format is detected from
+// the content, see WithFormat.
 //
-// From automatically unpacks the following archives based on their file
-// extension:
-//   - gzip: .gz
+// From automatically decompresses the following formats based on their
+// file extension, see RegisterDecompressor:
+//   - gzip: .gz, .tgz
+//   - bzip2: .bz2
+//   - xz: .xz
+//   - zstd: .zst, .zstd
+//   - lz4: .lz4
+//   - zlib: .zz, .zlib
+//
+// Records in zip and tar archives are read from all members with
+// a known format, see FromZip.
+//
+// From is a wrapper around All, returning the results in a channel.
 func From(path string, opts ...FromOption) (chan FromResult, error) {
 	cfg := newFromConfig(opts...)
+	seq, err := openFile(cfg, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.channel(seq), nil
+}
+
+// FromContext is From with the passed context, see WithContext.
+func FromContext(ctx context.Context, path string, opts ...FromOption) (chan FromResult, error) {
+	return From(path, append(opts, WithContext(ctx))...)
+}
 
-	reader, err := os.Open(path)
+// All returns an iterator over the maps parsed from a file, see From
+// for the handled formats.
+//
+// The file is parsed while iterating and closed once the iterator is
+// exhausted or the loop is stopped.
+// Errors opening the file are yielded as the only element.
+//
+// The iterator can only be used once.
+func All(path string, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	seq, err := openFile(cfg, path, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("dataparse: error opening file: %w", err)
+		return errSeq(err)
 	}
-	cfg.reader = reader
-	cfg.closers = append(cfg.closers, reader.Close)
+	return seq
+}
 
-	ext := filepath.Ext(path)
+// FromReader returns maps parsed from a reader like a network
+// connection or an HTTP body.
+//
+// The compression and format are detected from the content unless
+// a format is passed with WithFormat. Up to 8 KiB of the input are read
+// before the format is detected.
+//
+// FromReader is a wrapper around ReaderAll, returning the results in
+// a channel.
+func FromReader(reader io.Reader, opts ...FromOption) (chan FromResult, error) {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	seq, err := cfg.open("", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.channel(seq), nil
+}
 
-	switch ext {
-	case ".gz":
-		gzReader, err := gzip.NewReader(reader)
-		if err != nil {
-			return nil, fmt.Errorf("dataparse: error creating gzip reader: %w", err)
-		}
-		cfg.reader = gzReader
-		cfg.closers = append(cfg.closers, gzReader.Close)
+// ReaderAll returns an iterator over the maps parsed from a reader, see
+// FromReader.
+// Errors detecting the format are yielded as the only element.
+func ReaderAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	seq, err := cfg.open("", opts...)
+	if err != nil {
+		return errSeq(err)
 	}
+	return seq
+}
 
-	if reader != cfg.reader {
-		ext = filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path)))
+// errSeq returns an iterator yielding only the passed error.
+func errSeq(err error) iter.Seq2[*Map, error] {
+	return func(yield func(*Map, error) bool) {
+		yield(nil, err)
 	}
+}
 
-	var fn func(cfg *fromConfig) chan FromResult
-	switch ext {
-	case ".json", ".ndjson":
-		fn = fromJson
-	case ".csv":
-		fn = fromCsv
-	case ".tsv":
-		// Default to tab as separator for .tsv
-		c2 := newFromConfig(append([]FromOption{WithSeparator("\t")}, opts...)...)
-		cfg.separator = c2.separator
-		fn = fromCsv
-	default:
-		return nil, fmt.Errorf("dataparse: unhandled file extension: %q", ext)
+// openFile opens the file at path for cfg and returns the iterator of
+// the format matching the file extension.
+func openFile(cfg *fromConfig, path string, opts ...FromOption) (iter.Seq2[*Map, error], error) {
+	return openFileFrom(osFileSystem, cfg, path, opts...)
+}
+
+// openFileFrom is openFile for files in the passed file system.
+func openFileFrom(files fileSystem, cfg *fromConfig, path string, opts ...FromOption) (iter.Seq2[*Map, error], error) {
+	reader, err := files.open(path)
+	if err != nil {
+		return nil, fmt.Errorf("dataparse: error opening file: %w", err)
+	}
+	cfg.reader = reader
+	cfg.closers = append(cfg.closers, reader.Close)
+	if file, ok := reader.(*os.File); ok {
+		cfg.interrupts = append(cfg.interrupts, file.Close)
 	}
 
-	return fn(cfg), nil
+	// the path of each file is recorded even if WithSource is passed,
+	// e.g. for files matching a glob
+	return cfg.open(path, slices.Concat(opts, []FromOption{WithSource(path)})...)
 }
 
-// FromSingle is a wrapper around From and returns the first map and
+// FromSingle is a wrapper around All and returns the first map and
 // error in the result set.
 // It is only intended for instances where it is already known that the
 // input can only contain a single document.
+//
+// Reading is stopped and the file is closed after the first result.
 func FromSingle(path string, opts ...FromOption) (*Map, error) {
-	ch, err := From(path, append(opts, WithChannelSize(1))...)
-	if err != nil {
-		return nil, err
+	return single(All(path, opts...))
+}
+
+// single returns the first result of seq.
+// If seq yields no results ErrNoResult is returned.
+func single(seq iter.Seq2[*Map, error]) (*Map, error) {
+	for m, err := range seq {
+		return m, err
 	}
-	elem := <-ch
-	return elem.Map, elem.Err
+	return nil, ErrNoResult
 }
 
-// FromJson returns maps parsed from a stream which may consist of:
-// 1. A single JSON document
-// 2. A stream of JSON documents
-// 3. An array of JSON documents
-func FromJson(reader io.Reader, opts ...FromOption) chan FromResult {
+// FromCsv returns maps read from a CSV stream.
+//
+// FromCsv is a wrapper around CsvAll, returning the results in
+// a channel.
+func FromCsv(reader io.Reader, opts ...FromOption) chan FromResult {
 	cfg := newFromConfig(opts...)
 	cfg.reader = reader
-	return fromJson(cfg)
+	return cfg.channel(fromCsv(cfg))
 }
 
-// FromJsonSingle is a wrapper around FromJson and returns the first map
-// and error in the result set.
-// It is only intended for instances where it is already known that the
-// input can only contain a single document.
-func FromJsonSingle(reader io.Reader, opts ...FromOption) (*Map, error) {
-	elem := <-FromJson(reader, append(opts, WithChannelSize(1))...)
-	return elem.Map, elem.Err
+// FromCsvContext is FromCsv with the passed context, see WithContext.
+func FromCsvContext(ctx context.Context, reader io.Reader, opts ...FromOption) chan FromResult {
+	return FromCsv(reader, append(opts, WithContext(ctx))...)
 }
 
-func fromJson(cfg *fromConfig) chan FromResult {
-	ch := make(chan FromResult, cfg.channelSize)
+// CsvAll returns an iterator over the maps read from a CSV stream.
+func CsvAll(reader io.Reader, opts ...FromOption) iter.Seq2[*Map, error] {
+	cfg := newFromConfig(opts...)
+	cfg.reader = reader
+	return fromCsv(cfg)
+}
 
-	decoder := json.NewDecoder(cfg.reader)
+func fromCsv(cfg *fromConfig) iter.Seq2[*Map, error] {
+	return cfg.seq(func(yield func(*Map, error) bool) {
+		if len(cfg.separator) != 1 {
+			yield(nil, fmt.Errorf("dataparse: separator must be a string of length one for csv, got %q", cfg.separator))
+			return
+		}
 
-	go func() {
-		defer close(ch)
-		defer func() {
-			if err := cfg.Close(); err != nil {
-				ch <- FromResult{Err: fmt.Errorf("dataparse: error closing reader: %w", err)}
-			}
-		}()
+		records := &csvRecords{
+			lines:  &csvLines{reader: bufio.NewReader(cfg.reader)},
+			comma:  rune(cfg.separator[0]),
+			trim:   cfg.trimSpace,
+			fields: len(cfg.headers),
+		}
+		records.newReader()
 
-		for decoder.More() {
-			// decoder refuses to decode into Map or map[any]any
-			var m any
-			if err := decoder.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
-				ch <- FromResult{Err: err}
+		headers := cfg.headers
+		if len(headers) == 0 {
+			h, _, _, err := records.read()
+			if err != nil {
+				yield(nil, err)
 				return
 			}
-			val := reflect.ValueOf(m)
-			switch val.Kind() {
-			case reflect.Slice:
-				for i := 0; i < val.Len(); i++ {
-					elem, err := NewMap(val.Index(i).Interface())
-					if err != nil {
-						ch <- FromResult{Err: fmt.Errorf("dataparse: error parsing element %d: %w", i, err)}
-						return
-					}
-					ch <- FromResult{Map: elem}
+			// the record is reused by the next read
+			headers = slices.Clone(h)
+		}
+
+		for index := 0; ; index++ {
+			elems, line, offset, err := records.read()
+			p := Provenance{
+				Source: cfg.source,
+				Member: cfg.member,
+				Index:  index,
+				Line:   line,
+				Offset: offset,
+			}
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return
 				}
-			case reflect.Struct, reflect.Map:
-				mMap, err := NewMap(m)
-				if err != nil {
-					ch <- FromResult{Err: err}
+				var parseErr *csv.ParseError
+				if !errors.As(err, &parseErr) {
+					yield(nil, NewErrRecord(p, err))
 					return
 				}
-				ch <- FromResult{Map: mMap}
-			default:
-				ch <- FromResult{Err: fmt.Errorf("dataparse: unhandled type %q in file", val.Kind())}
+				if !cfg.recordError(yield, NewErrRecord(p, err)) {
+					return
+				}
+				continue
+			}
+
+			m := NewEmptyMap()
+			for i := range elems {
+				m.Data[headers[i]] = elems[i]
+			}
+			m.Provenance = p
+			if !yield(m, nil) {
 				return
 			}
 		}
-	}()
+	})
+}
 
-	return ch
+// csvRecords reads the records of CSV input with a csv.Reader.
+//
+// Bare quotes are accepted in records on a single line like with
+// LazyQuotes. Records spanning multiple lines must be quoted properly,
+// otherwise the quote is likely unterminated and swallowed the
+// following lines. Reading continues with the line after the start of
+// such records.
+type csvRecords struct {
+	lines  *csvLines
+	reader *csv.Reader
+	comma  rune
+	trim   bool
+	fields int
+
+	// line and offset are the position in the input the reader
+	// started at
+	line   int
+	offset int64
 }
 
-// FromCsv returns maps read from a CSV stream.
-func FromCsv(reader io.Reader, opts ...FromOption) chan FromResult {
-	cfg := newFromConfig(opts...)
-	cfg.reader = reader
-	return fromCsv(cfg)
+func (r *csvRecords) newReader() {
+	r.reader = csv.NewReader(r.lines)
+	r.reader.Comma = r.comma
+	r.reader.FieldsPerRecord = r.fields
+	r.reader.TrimLeadingSpace = r.trim
+	r.reader.ReuseRecord = true
+	r.line = r.lines.line
+	r.offset = r.lines.offset
 }
 
-func fromCsv(cfg *fromConfig) chan FromResult {
-	ch := make(chan FromResult, cfg.channelSize)
+// read returns the next record with the line and offset it starts at.
+// Malformed records are returned with a *csv.ParseError.
+func (r *csvRecords) read() ([]string, int, int64, error) {
+	r.lines.reset()
+	offset := r.offset + r.reader.InputOffset()
+	record, err := r.reader.Read()
+	if err == nil {
+		r.fields = len(record)
+		line, _ := r.reader.FieldPos(0)
+		return record, r.line + line, offset, nil
+	}
 
-	if len(cfg.separator) != 1 {
-		defer close(ch)
-		defer func() {
-			if err := cfg.Close(); err != nil {
-				ch <- FromResult{Err: fmt.Errorf("dataparse: error closing reader: %w", err)}
-			}
-		}()
-		ch <- FromResult{Err: fmt.Errorf("dataparse: separator must be a string of length one for csv, got %q", cfg.separator)}
-		return ch
+	var parseErr *csv.ParseError
+	if !errors.As(err, &parseErr) {
+		return nil, 0, offset, err
+	}
+	line := r.line
+	parseErr.StartLine += line
+	parseErr.Line += line
+	if errors.Is(err, csv.ErrFieldCount) {
+		// the record was read completely
+		return nil, parseErr.StartLine, offset, err
+	}
+
+	if parseErr.StartLine == parseErr.Line {
+		if record, ok := r.lazy(r.lines.last()); ok {
+			return record, parseErr.StartLine, offset, nil
+		}
 	}
 
-	reader := csv.NewReader(cfg.reader)
-	reader.Comma = rune(cfg.separator[0])
-	reader.FieldsPerRecord = len(cfg.headers)
+	// read the lines after the start of the record again with a new
+	// reader as the reader cannot continue in the middle of a record
+	r.lines.unread(parseErr.StartLine)
+	r.newReader()
+	return nil, parseErr.StartLine, offset, err
+}
+
+// lazy parses a record on a single line with LazyQuotes.
+func (r *csvRecords) lazy(line []byte) ([]string, bool) {
+	reader := csv.NewReader(bytes.NewReader(line))
+	reader.Comma = r.comma
+	reader.FieldsPerRecord = r.fields
+	reader.TrimLeadingSpace = r.trim
 	reader.LazyQuotes = true
-	reader.TrimLeadingSpace = cfg.trimSpace
 
-	go func() {
-		defer close(ch)
-		defer func() {
-			if err := cfg.Close(); err != nil {
-				ch <- FromResult{Err: fmt.Errorf("dataparse: error closing reader: %w", err)}
-			}
-		}()
+	record, err := reader.Read()
+	if err != nil {
+		return nil, false
+	}
+	for _, field := range record {
+		// a quoted field that is not terminated on the line
+		if strings.Contains(field, "\n") {
+			return nil, false
+		}
+	}
+	return record, true
+}
 
-		if len(cfg.headers) == 0 {
-			h, err := reader.Read()
-			if err != nil {
-				ch <- FromResult{Err: err}
-				return
-			}
-			cfg.headers = h
+// csvLines passes the input to a csv.Reader one line at a time, which
+// keeps the lines of the current record available to read them again.
+type csvLines struct {
+	reader *bufio.Reader
+	// buf holds the lines read since the last reset, ends their ends
+	// and pos the position up to which buf was read
+	buf  []byte
+	ends []int
+	pos  int
+	// pending are the lines to read again after a malformed record
+	pending [][]byte
+
+	// line and offset are the number of lines and bytes read
+	line   int
+	offset int64
+}
+
+func (l *csvLines) Read(p []byte) (int, error) {
+	if l.pos == len(l.buf) {
+		if err := l.next(); err != nil {
+			return 0, err
 		}
+	}
+	n := copy(p, l.buf[l.pos:])
+	l.pos += n
+	return n, nil
+}
 
+// next appends the next line to buf.
+func (l *csvLines) next() error {
+	start := len(l.buf)
+	if len(l.pending) > 0 {
+		l.buf = append(l.buf, l.pending[0]...)
+		l.pending = l.pending[1:]
+	} else {
 		for {
-			elems, err := reader.Read()
-			if err != nil {
-				if errors.Is(err, io.EOF) {
-					return
+			line, err := l.reader.ReadSlice('\n')
+			l.buf = append(l.buf, line...)
+			if errors.Is(err, bufio.ErrBufferFull) {
+				continue
+			}
+			if len(l.buf) == start {
+				if err == nil {
+					err = io.EOF
 				}
-				ch <- FromResult{Err: err}
-				return
+				return err
 			}
+			break
+		}
+	}
 
-			m := NewEmptyMap()
-			for i := range elems {
-				m.Data[cfg.headers[i]] = elems[i]
+	l.ends = append(l.ends, len(l.buf))
+	l.line++
+	l.offset += int64(len(l.buf) - start)
+	return nil
+}
+
+// reset drops the lines read so far. It must only be called once the
+// reader consumed all lines.
+func (l *csvLines) reset() {
+	l.buf = l.buf[:0]
+	l.ends = l.ends[:0]
+	l.pos = 0
+}
+
+// last returns the last line read since the last reset.
+func (l *csvLines) last() []byte {
+	if len(l.ends) < 2 {
+		return l.buf
+	}
+	return l.buf[l.ends[len(l.ends)-2]:]
+}
+
+// unread queues the lines read since the last reset after the line
+// with the given number to be read again.
+func (l *csvLines) unread(line int) {
+	n := min(l.line-line, len(l.ends))
+	if n > 0 {
+		lines := make([][]byte, 0, n+len(l.pending))
+		start := 0
+		for i, end := range l.ends {
+			if i >= len(l.ends)-n {
+				lines = append(lines, slices.Clone(l.buf[start:end]))
+				l.offset -= int64(end - start)
 			}
-			ch <- FromResult{Map: m}
+			start = end
 		}
-	}()
-
-	return ch
+		l.pending = append(lines, l.pending...)
+		l.line -= n
+	}
+	l.reset()
 }
 
 // FromKVString returns a map based on the passed string.
 //
+// Pairs are separated by the separator, see WithSeparator, and keys
+// and values by the delimiter, see WithKVDelimiter.
+// Keys and values can be quoted with double quotes to contain
+// separators and delimiters, quotes inside are escaped with
+// a backslash. Quotes without a closing quote are part of the key or
+// value.
+//
+// Empty pairs, e.g. between repeated or trailing separators, are
+// skipped instead of being returned as an empty key without a value.
+// Pairs with an empty key and a value like "=1" are kept.
+//
 // Example:
 //
-//	input: a=1,b=test,c
+//	input: a=1,b=test,c,d="x,y"
 //	output: {
 //		a: 1,
 //		b: "test",
 //		c: nil,
+//		d: "x,y",
 //	}
 func FromKVString(kv string, opts ...FromOption) (*Map, error) {
 	cfg := newFromConfig(opts...)
 
 	m := NewEmptyMap(opts...)
-	for _, elem := range strings.Split(kv, cfg.separator) {
-		split := strings.SplitN(elem, "=", 2)
-
-		key := strings.TrimSpace(split[0])
-		var value any
-		if len(split) > 1 {
-			if cfg.trimSpace {
-				value = strings.TrimSpace(split[1])
-			} else {
-				value = split[1]
-			}
-		}
-
-		m.Data[key] = value
-	}
-
+	m.Data = parseKV(kv, stringSeparator(cfg.separator), cfg.kvDelimiter, cfg.trimSpace)
 	return m, nil
 }
 
@@ -1,13 +1,17 @@
 package dataparse
 
 import (
+	"bytes"
 	"compress/gzip"
+	"context"
 	"io"
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -203,6 +207,145 @@ func TestFrom_Csv(t *testing.T) {
 	)
 }
 
+// testCancelWhileReading cancels the context while the producer is
+// blocked reading from a pipe after the first record of data, which is
+// decompressed based on ext.
+func testCancelWhileReading(t *testing.T, ext string, data []byte) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the pipe blocks reads until data is written or it is closed
+	reader, writer, err := os.Pipe()
+	require.Nil(t, err)
+	defer writer.Close()
+	go writer.Write(data) //nolint:errcheck
+
+	// set up like openFile does for files
+	interrupted := make(chan struct{})
+	cfg := newFromConfig(WithContext(ctx), WithChannelSize(0))
+	cfg.reader = reader
+	cfg.closers = append(cfg.closers, reader.Close)
+	cfg.interrupts = append(cfg.interrupts, func() error {
+		close(interrupted)
+		return reader.Close()
+	})
+	if ext != "" {
+		require.Nil(t, cfg.decompress(ext, decompressors[ext]))
+	}
+
+	maps := cfg.channel(fromCsv(cfg))
+	m := <-maps
+	require.Nil(t, m.Err)
+	assert.Equal(t, 1, m.Map.MustGet("a").MustInt())
+
+	// the producer is blocked reading the next line, cancelling closes
+	// the file and the channel without sending further results
+	cancel()
+	select {
+	case <-interrupted:
+	case <-time.After(time.Second):
+		t.Fatal("reader was not closed after cancelling the context")
+	}
+	for m := range maps {
+		t.Fatalf("unexpected result: %v", m)
+	}
+}
+
+func TestFromContext_Cancel(t *testing.T) {
+	testCancelWhileReading(t, "", []byte("a,b\n1,2\n"))
+}
+
+func TestFromContext_CancelCompressed(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := zstd.NewWriter(buf)
+	require.Nil(t, err)
+	_, err = w.Write([]byte("a,b\n1,2\n"))
+	require.Nil(t, err)
+	// flush the block without ending the frame so the decoder waits
+	// for more data
+	require.Nil(t, w.Flush())
+
+	testCancelWhileReading(t, ".zst", buf.Bytes())
+}
+
+func TestFromJsonContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	maps := FromJsonContext(ctx, strings.NewReader(`{"a": 1}{"a": 2}`))
+	for m := range maps {
+		t.Fatalf("unexpected result: %v", m)
+	}
+}
+
+func TestFromJsonSingle_Empty(t *testing.T) {
+	_, err := FromJsonSingle(strings.NewReader(""))
+	require.ErrorIs(t, err, ErrNoResult)
+}
+
+func TestAll_Csv(t *testing.T) {
+	count := 0
+	for m, err := range All("./testdata/data.csv") {
+		require.Nil(t, err)
+		count++
+		assert.Equal(t, count, m.MustGet("id").MustInt())
+	}
+	assert.Equal(t, 10, count)
+}
+
+func TestAll_Missing(t *testing.T) {
+	count := 0
+	for m, err := range All("./testdata/missing.csv") {
+		require.NotNil(t, err)
+		require.Nil(t, m)
+		count++
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestJsonAll(t *testing.T) {
+	ids := []int{}
+	for m, err := range JsonAll(strings.NewReader(`{"id": 1} [{"id": 2}, {"id": 3}]`)) {
+		require.Nil(t, err)
+		ids = append(ids, m.MustGet("id").MustInt())
+	}
+	assert.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func TestCsvAll_Break(t *testing.T) {
+	closed := false
+	cfg := newFromConfig()
+	cfg.reader = strings.NewReader("a,b\n1,2\n3,4\n")
+	cfg.closers = append(cfg.closers, func() error {
+		closed = true
+		return nil
+	})
+
+	for m, err := range fromCsv(cfg) {
+		require.Nil(t, err)
+		assert.Equal(t, 1, m.MustGet("a").MustInt())
+		break
+	}
+	assert.True(t, closed)
+}
+
+func TestCsvAll_LongLines(t *testing.T) {
+	long := strings.Repeat("x", 10000)
+	input := "a,b\r\n1," + long + "\r\n2,\"" + long + "\r\n" + long + "\"\r\n"
+
+	results := []*Map{}
+	for m, err := range CsvAll(strings.NewReader(input)) {
+		require.Nil(t, err)
+		results = append(results, m)
+	}
+	require.Len(t, results, 2)
+	assert.Equal(t, long, results[0].MustGet("b").MustString())
+	assert.Equal(t, long+"\n"+long, results[1].MustGet("b").MustString())
+	assert.Equal(t, 3, results[1].Provenance.Line)
+	assert.Equal(t, int64(len(long)+9), results[1].Provenance.Offset)
+}
+
 func TestFromKVString(t *testing.T) {
 	m, err := FromKVString("a=1,b=test,c,d=0x05")
 	require.Nil(t, err)
@@ -217,6 +360,56 @@ func TestFromKVString(t *testing.T) {
 	assert.Equal(t, "value2", m.MustGet("key2").MustString())
 }
 
+func TestFromKVString_Quotes(t *testing.T) {
+	m, err := FromKVString(`a="x,y",b="say \"hi\"",c=`)
+	require.Nil(t, err)
+	assert.Equal(t, "x,y", m.MustGet("a").MustString())
+	assert.Equal(t, `say "hi"`, m.MustGet("b").MustString())
+	assert.Equal(t, "", m.MustGet("c").MustString())
+
+	// quotes without a closing quote are kept
+	m, err = FromKVString(`size=5",name=a`)
+	require.Nil(t, err)
+	assert.Equal(t, map[any]any{
+		"size": `5"`,
+		"name": "a",
+	}, m.Data)
+
+	m, err = FromKVString(`a="unterminated,b=1`)
+	require.Nil(t, err)
+	assert.Equal(t, map[any]any{
+		"a": `"unterminated`,
+		"b": "1",
+	}, m.Data)
+}
+
+func TestFromKVString_EmptyPairs(t *testing.T) {
+	m, err := FromKVString("a=1,,b=2, ,")
+	require.Nil(t, err)
+	assert.Equal(t, map[any]any{
+		"a": "1",
+		"b": "2",
+	}, m.Data)
+}
+
+func TestFromKVString_EmptyKey(t *testing.T) {
+	m, err := FromKVString("=1,b=2")
+	require.Nil(t, err)
+	assert.Equal(t, map[any]any{
+		"":  "1",
+		"b": "2",
+	}, m.Data)
+}
+
+func TestFromKVString_Delimiter(t *testing.T) {
+	input := "Content-Type: text/html\nHost: example.com:8080\n"
+	m, err := FromKVString(input, WithSeparator("\n"), WithKVDelimiter(":"))
+	require.Nil(t, err)
+	assert.Equal(t, "text/html", m.MustGet("Content-Type").MustString())
+	assert.Equal(t, "example.com:8080", m.MustGet("Host").MustString())
+	assert.Len(t, m.Data, 2)
+}
+
 func TestNewMap(t *testing.T) {
 	// convert maps to Map
 	m, err := NewMap(map[any]any{1: 1})
@@ -0,0 +1,50 @@
+package dataparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Provenance describes where a record was read from.
+type Provenance struct {
+	// Source is the path of the file the record was read from or the
+	// name passed with WithSource.
+	Source string
+	// Member is the path of the file inside an archive the record was
+	// read from.
+	// Empty if the record was not read from an archive.
+	Member string
+	// Index is the zero-based index of the record in the source or
+	// archive member.
+	Index int
+	// Line is the one-based line the record starts at for line based
+	// formats like CSV or the row for workbooks.
+	// Zero if the format is not line based.
+	Line int
+	// Offset is the byte offset the record starts at in the
+	// decompressed input.
+	Offset int64
+}
+
+// String returns a human readable description of the provenance, e.g.
+// "data.csv record 48212 line 48213" or
+// "bundle.zip:data.csv record 48212 line 48213" for archives.
+// The line is preferred over the offset if it is known.
+func (p Provenance) String() string {
+	parts := []string{}
+	switch {
+	case p.Source != "" && p.Member != "":
+		parts = append(parts, p.Source+":"+p.Member)
+	case p.Source != "":
+		parts = append(parts, p.Source)
+	case p.Member != "":
+		parts = append(parts, p.Member)
+	}
+	parts = append(parts, fmt.Sprintf("record %d", p.Index))
+	if p.Line > 0 {
+		parts = append(parts, fmt.Sprintf("line %d", p.Line))
+	} else {
+		parts = append(parts, fmt.Sprintf("offset %d", p.Offset))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,86 @@
+package dataparse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenance_String(t *testing.T) {
+	assert.Equal(t, "data.csv record 48212 line 48213",
+		Provenance{Source: "data.csv", Index: 48212, Line: 48213, Offset: 5}.String())
+	assert.Equal(t, "record 0 offset 15",
+		Provenance{Offset: 15}.String())
+	assert.Equal(t, "bundle.zip:data.csv record 1 line 2",
+		Provenance{Source: "bundle.zip", Member: "data.csv", Index: 1, Line: 2}.String())
+}
+
+func TestProvenance_Csv(t *testing.T) {
+	index := 0
+	for m, err := range All("./testdata/data.csv") {
+		require.Nil(t, err)
+		assert.Equal(t, "./testdata/data.csv", m.Provenance.Source)
+		assert.Equal(t, index, m.Provenance.Index)
+		assert.Equal(t, index+2, m.Provenance.Line)
+		index++
+	}
+
+	m, err := FromSingle("./testdata/data.csv")
+	require.Nil(t, err)
+	// the header line is 58 bytes long including the newline
+	assert.Equal(t, int64(58), m.Provenance.Offset)
+}
+
+func TestProvenance_CsvError(t *testing.T) {
+	input := "a,b\n1,2\n3,4,5\n"
+
+	var lastErr error
+	for _, err := range CsvAll(strings.NewReader(input), WithSource("input.csv")) {
+		lastErr = err
+	}
+
+	var errRecord ErrRecord
+	require.True(t, errors.As(lastErr, &errRecord))
+	assert.Equal(t, "input.csv", errRecord.Source)
+	assert.Equal(t, 1, errRecord.Index)
+	assert.Equal(t, 3, errRecord.Line)
+	assert.Equal(t, int64(8), errRecord.Offset)
+	assert.Contains(t, lastErr.Error(), "input.csv record 1 line 3")
+}
+
+func TestProvenance_Json(t *testing.T) {
+	input := `{"a": 1}
+  [{"a": 2},  {"a": 3}]`
+
+	offsets := []int64{}
+	for m, err := range JsonAll(strings.NewReader(input)) {
+		require.Nil(t, err)
+		offsets = append(offsets, m.Provenance.Offset)
+		assert.Equal(t, len(offsets)-1, m.Provenance.Index)
+	}
+	assert.Equal(t, []int64{0, 12, 23}, offsets)
+}
+
+func TestProvenance_JsonError(t *testing.T) {
+	var lastErr error
+	for _, err := range JsonAll(strings.NewReader(`{"a": 1} {"a": }`)) {
+		lastErr = err
+	}
+
+	var errRecord ErrRecord
+	require.True(t, errors.As(lastErr, &errRecord))
+	assert.Equal(t, 1, errRecord.Index)
+	assert.Equal(t, int64(9), errRecord.Offset)
+}
+
+func TestProvenance_Xlsx(t *testing.T) {
+	lines := []int{}
+	for m, err := range All("./testdata/data.xlsx", WithSheet("hosts")) {
+		require.Nil(t, err)
+		lines = append(lines, m.Provenance.Line)
+	}
+	assert.Equal(t, []int{2, 4}, lines)
+}
@@ -0,0 +1,443 @@
+package dataparse
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coercion is a type all values of a field can be converted to with
+// the methods of Value, see FieldSchema.Coercions.
+type Coercion string
+
+const (
+	// CoercionBool reports that Value.Bool succeeds for all values.
+	CoercionBool Coercion = "Bool"
+	// CoercionInt64 reports that all values are integers, e.g. int,
+	// integral floats or strings like "42", and Value.Int64 does not
+	// truncate them.
+	CoercionInt64 Coercion = "Int64"
+	// CoercionFloat64 reports that all values are numbers or strings
+	// that parse as numbers.
+	CoercionFloat64 Coercion = "Float64"
+	// CoercionTime reports that all values are time.Time or strings
+	// that parse with one of ParseTimeFormats, see
+	// FieldSchema.TimeFormat.
+	CoercionTime Coercion = "Time"
+)
+
+var coercions = []Coercion{CoercionBool, CoercionInt64, CoercionFloat64, CoercionTime}
+
+// Schema describes the keys of maps, see InferSchema.
+type Schema struct {
+	// Count is the number of maps.
+	Count int
+	// Fields are the keys of the maps, sorted by key.
+	Fields []*FieldSchema
+}
+
+// Field returns the schema of the field with the given key or nil.
+func (s *Schema) Field(key string) *FieldSchema {
+	for _, field := range s.Fields {
+		if field.Key == key {
+			return field
+		}
+	}
+	return nil
+}
+
+// FieldSchema describes the values of a key.
+type FieldSchema struct {
+	// Key is the key as found in the maps, converted to a string.
+	Key string
+	// Count is the number of maps with the key, or the number of
+	// elements for list items.
+	Count int
+	// Missing is the number of maps without the key.
+	Missing int
+	// Nulls is the number of nil values.
+	Nulls int
+	// Empty is the number of empty or whitespace-only strings.
+	Empty int
+
+	// GoTypes are the observed Go types with the number of values,
+	// e.g. "string" or "map[any]any".
+	GoTypes map[string]int
+	// JSONTypes are the observed types in terms of JSON with the
+	// number of values: "null", "string", "number", "boolean", "object"
+	// and "array".
+	JSONTypes map[string]int
+
+	// Coercions are the types all values that are neither nil nor
+	// empty can be converted to.
+	Coercions []Coercion
+	// TimeFormat is the first of ParseTimeFormats that parses all
+	// string values if the field can be coerced to CoercionTime.
+	TimeFormat string
+
+	// Cardinality is the number of distinct values that are neither
+	// nil nor empty, excluding maps and lists. Counting stops at the
+	// limit passed with WithSchemaCardinalityLimit.
+	Cardinality int
+	// Examples are the first distinct values, excluding maps and
+	// lists, see WithSchemaExamples.
+	Examples []any
+
+	// Fields describes the keys of values that are maps.
+	Fields *Schema
+	// Items describes the elements of values that are lists.
+	Items *FieldSchema
+}
+
+// Nullable returns true if the field is missing in maps or has nil or
+// empty values.
+func (f *FieldSchema) Nullable() bool {
+	return f.Missing > 0 || f.Nulls > 0 || f.Empty > 0
+}
+
+// Can returns true if all values can be converted to the type.
+func (f *FieldSchema) Can(c Coercion) bool {
+	return slices.Contains(f.Coercions, c)
+}
+
+// InferSchema reads the maps of seq and describes their keys and
+// values, recursing into nested maps and lists.
+//
+// The schema reports the observed types of the values as well as the
+// types the values can be converted to, e.g. a CSV column can hold
+// only strings that all parse as integers.
+//
+// Reading from seq stops at the first error, which is returned.
+func InferSchema(seq iter.Seq2[*Map, error], opts ...FromOption) (*Schema, error) {
+	cfg := newFromConfig(opts...)
+
+	schema := newSchemaBuilder(cfg)
+	if err := cfg.each(seq, func(m *Map) error {
+		schema.add(m.Data)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return schema.schema(), nil
+}
+
+type schemaBuilder struct {
+	cfg    *fromConfig
+	count  int
+	fields map[string]*fieldBuilder
+}
+
+func newSchemaBuilder(cfg *fromConfig) *schemaBuilder {
+	return &schemaBuilder{
+		cfg:    cfg,
+		fields: map[string]*fieldBuilder{},
+	}
+}
+
+func (b *schemaBuilder) add(data map[any]any) {
+	b.count++
+	for key, value := range data {
+		name := fmt.Sprint(key)
+		field, ok := b.fields[name]
+		if !ok {
+			field = newFieldBuilder(b.cfg, name)
+			b.fields[name] = field
+		}
+		field.Count++
+		field.add(value)
+	}
+}
+
+func (b *schemaBuilder) schema() *Schema {
+	schema := &Schema{
+		Count:  b.count,
+		Fields: make([]*FieldSchema, 0, len(b.fields)),
+	}
+	for _, field := range b.fields {
+		field.Missing = b.count - field.Count
+		schema.Fields = append(schema.Fields, field.schema())
+	}
+	slices.SortFunc(schema.Fields, func(a, b *FieldSchema) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return schema
+}
+
+type fieldBuilder struct {
+	FieldSchema
+
+	cfg *fromConfig
+
+	// values counts the values that are neither nil nor empty
+	values    int
+	distinct  map[any]struct{}
+	coercible map[Coercion]bool
+	// layouts are the time layouts parsing all string values so far,
+	// nil until the first string value
+	layouts []string
+
+	fields *schemaBuilder
+	items  *fieldBuilder
+}
+
+func newFieldBuilder(cfg *fromConfig, key string) *fieldBuilder {
+	b := &fieldBuilder{
+		FieldSchema: FieldSchema{
+			Key:       key,
+			GoTypes:   map[string]int{},
+			JSONTypes: map[string]int{},
+		},
+		cfg:       cfg,
+		distinct:  map[any]struct{}{},
+		coercible: map[Coercion]bool{},
+	}
+	for _, c := range coercions {
+		b.coercible[c] = true
+	}
+	return b
+}
+
+func (b *fieldBuilder) add(value any) {
+	if m, ok := value.(*Map); ok {
+		value = m.Data
+	}
+
+	b.GoTypes[goTypeName(value)]++
+	b.JSONTypes[jsonTypeName(value)]++
+
+	switch typed := value.(type) {
+	case nil:
+		b.Nulls++
+		return
+	case string:
+		if strings.TrimSpace(typed) == "" {
+			b.Empty++
+			return
+		}
+	case map[any]any:
+		b.addMap(typed)
+		return
+	case map[string]any:
+		converted := make(map[any]any, len(typed))
+		for key, value := range typed {
+			converted[key] = value
+		}
+		b.addMap(converted)
+		return
+	}
+
+	if items, ok := listValues(value); ok {
+		b.values++
+		b.notCoercible()
+		if b.items == nil {
+			b.items = newFieldBuilder(b.cfg, b.Key+"[]")
+		}
+		for _, item := range items {
+			b.items.Count++
+			b.items.add(item)
+		}
+		return
+	}
+
+	b.values++
+	b.example(value)
+	for _, c := range coercions {
+		if b.coercible[c] && !b.coerce(c, value) {
+			b.coercible[c] = false
+		}
+	}
+}
+
+func (b *fieldBuilder) addMap(m map[any]any) {
+	b.values++
+	b.notCoercible()
+	if b.fields == nil {
+		b.fields = newSchemaBuilder(b.cfg)
+	}
+	b.fields.add(m)
+}
+
+func (b *fieldBuilder) notCoercible() {
+	for _, c := range coercions {
+		b.coercible[c] = false
+	}
+}
+
+// example records value as distinct value and as example.
+func (b *fieldBuilder) example(value any) {
+	var key any
+	switch typed := value.(type) {
+	case []byte:
+		key = bytesKey(typed)
+	case *big.Rat:
+		key = ratKey(ratString(typed))
+	case time.Time:
+		// the location is not comparable by value
+		key = timeKey(typed.UnixNano())
+	default:
+		if !reflect.TypeOf(value).Comparable() {
+			key = fmt.Sprintf("%T:%v", value, value)
+		} else {
+			key = value
+		}
+	}
+
+	if _, ok := b.distinct[key]; ok || len(b.distinct) >= b.cfg.schemaCardinalityLimit {
+		return
+	}
+	b.distinct[key] = struct{}{}
+	if len(b.Examples) < b.cfg.schemaExamples {
+		b.Examples = append(b.Examples, value)
+	}
+}
+
+type (
+	bytesKey string
+	ratKey   string
+	timeKey  int64
+)
+
+// coerce returns true if value can be converted to c.
+func (b *fieldBuilder) coerce(c Coercion, value any) bool {
+	switch c {
+	case CoercionBool:
+		switch value.(type) {
+		case time.Time, []byte, *big.Rat:
+			return false
+		}
+		_, err := NewValue(value).Bool()
+		return err == nil
+	case CoercionInt64:
+		return isInt64(value)
+	case CoercionFloat64:
+		switch typed := value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, *big.Rat:
+			return true
+		case string:
+			_, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+			return err == nil
+		}
+		return false
+	case CoercionTime:
+		switch typed := value.(type) {
+		case time.Time:
+			return true
+		case string:
+			return b.parseTime(strings.TrimSpace(typed))
+		}
+		return false
+	}
+	return false
+}
+
+// parseTime returns true if s parses with any of the layouts that
+// parsed the previous strings and drops the layouts that do not.
+func (b *fieldBuilder) parseTime(s string) bool {
+	if b.layouts == nil {
+		b.layouts = slices.Clone(ParseTimeFormats)
+	}
+	b.layouts = slices.DeleteFunc(b.layouts, func(layout string) bool {
+		_, err := time.Parse(layout, s)
+		return err != nil
+	})
+	return len(b.layouts) > 0
+}
+
+func isInt64(value any) bool {
+	switch typed := value.(type) {
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return true
+	case uint:
+		return uint64(typed) <= math.MaxInt64
+	case uint64:
+		return typed <= math.MaxInt64
+	case float32:
+		return isIntegral(float64(typed))
+	case float64:
+		return isIntegral(typed)
+	case *big.Rat:
+		return typed.IsInt() && typed.Num().IsInt64()
+	case string:
+		_, err := strconv.ParseInt(strings.TrimSpace(typed), 10, 64)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func isIntegral(f float64) bool {
+	return f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+}
+
+func (b *fieldBuilder) schema() *FieldSchema {
+	field := b.FieldSchema
+	field.Cardinality = len(b.distinct)
+
+	if b.values > 0 {
+		for _, c := range coercions {
+			if b.coercible[c] {
+				field.Coercions = append(field.Coercions, c)
+			}
+		}
+		if b.coercible[CoercionTime] && len(b.layouts) > 0 {
+			field.TimeFormat = b.layouts[0]
+		}
+	}
+
+	if b.fields != nil {
+		field.Fields = b.fields.schema()
+	}
+	if b.items != nil {
+		field.Items = b.items.schema()
+	}
+	return &field
+}
+
+// goTypeName returns the name of the Go type of v.
+func goTypeName(v any) string {
+	if v == nil {
+		return "nil"
+	}
+	return strings.ReplaceAll(fmt.Sprintf("%T", v), "interface {}", "any")
+}
+
+// jsonTypeName returns the name of the JSON type v is encoded as.
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, *big.Rat:
+		return "number"
+	case map[any]any, map[string]any, *Map:
+		return "object"
+	}
+	if _, ok := listValues(v); ok {
+		return "array"
+	}
+	return "string"
+}
+
+// listValues returns the items of v if it is a slice.
+// []byte is not a list as it is encoded as a base64 string.
+func listValues(v any) ([]any, bool) {
+	if list, ok := v.([]any); ok {
+		return list, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	list := make([]any, rv.Len())
+	for i := range list {
+		list[i] = rv.Index(i).Interface()
+	}
+	return list, true
+}
@@ -0,0 +1,155 @@
+package dataparse
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferSchema_Csv(t *testing.T) {
+	schema, err := InferSchema(All("./testdata/data.csv"))
+	require.Nil(t, err)
+
+	assert.Equal(t, 10, schema.Count)
+	keys := []string{}
+	for _, field := range schema.Fields {
+		keys = append(keys, field.Key)
+	}
+	assert.Equal(t, []string{"email", "first_name", "gender", "id", "ip_address", "last_name", "timestamp"}, keys)
+
+	id := schema.Field("id")
+	assert.Equal(t, map[string]int{"string": 10}, id.GoTypes)
+	assert.Equal(t, map[string]int{"string": 10}, id.JSONTypes)
+	assert.Equal(t, []Coercion{CoercionInt64, CoercionFloat64}, id.Coercions)
+	assert.Equal(t, 10, id.Cardinality)
+	assert.Equal(t, []any{"1", "2", "3"}, id.Examples)
+	assert.False(t, id.Nullable())
+
+	gender := schema.Field("gender")
+	assert.Empty(t, gender.Coercions)
+	assert.Equal(t, 4, gender.Cardinality)
+
+	timestamp := schema.Field("timestamp")
+	assert.Equal(t, []Coercion{CoercionTime}, timestamp.Coercions)
+	assert.Equal(t, time.RFC3339, timestamp.TimeFormat)
+}
+
+func TestInferSchema_Nested(t *testing.T) {
+	schema, err := InferSchema(mapsSeq(t,
+		map[string]any{
+			"name":    "alice",
+			"active":  true,
+			"score":   1.0,
+			"address": map[string]any{"city": "Berlin", "zip": "10115"},
+			"tags":    []any{"a", "b"},
+			"orders":  []any{map[string]any{"id": 1, "at": "2026-01-02"}},
+		},
+		map[string]any{
+			"name":    "bob",
+			"active":  "no",
+			"score":   1.5,
+			"address": map[string]any{"city": "Hamburg"},
+			"tags":    []any{},
+			"orders":  nil,
+		},
+		map[string]any{
+			"name":   "",
+			"active": false,
+			"score":  2.0,
+		},
+	))
+	require.Nil(t, err)
+	assert.Equal(t, 3, schema.Count)
+
+	name := schema.Field("name")
+	assert.Equal(t, 1, name.Empty)
+	assert.True(t, name.Nullable())
+	assert.Equal(t, 2, name.Cardinality)
+
+	active := schema.Field("active")
+	assert.Equal(t, map[string]int{"bool": 2, "string": 1}, active.GoTypes)
+	assert.Equal(t, map[string]int{"boolean": 2, "string": 1}, active.JSONTypes)
+	assert.Equal(t, []Coercion{CoercionBool}, active.Coercions)
+
+	score := schema.Field("score")
+	assert.Equal(t, []Coercion{CoercionFloat64}, score.Coercions)
+	assert.Equal(t, map[string]int{"number": 3}, score.JSONTypes)
+
+	address := schema.Field("address")
+	assert.Equal(t, 1, address.Missing)
+	assert.Equal(t, map[string]int{"object": 2}, address.JSONTypes)
+	assert.Empty(t, address.Coercions)
+	require.NotNil(t, address.Fields)
+	assert.Equal(t, 2, address.Fields.Count)
+	zip := address.Fields.Field("zip")
+	assert.Equal(t, 1, zip.Missing)
+	assert.Equal(t, []Coercion{CoercionInt64, CoercionFloat64}, zip.Coercions)
+
+	tags := schema.Field("tags")
+	require.NotNil(t, tags.Items)
+	assert.Equal(t, "tags[]", tags.Items.Key)
+	assert.Equal(t, 2, tags.Items.Count)
+	assert.Equal(t, []any{"a", "b"}, tags.Items.Examples)
+
+	orders := schema.Field("orders")
+	assert.Equal(t, 1, orders.Nulls)
+	require.NotNil(t, orders.Items)
+	require.NotNil(t, orders.Items.Fields)
+	id := orders.Items.Fields.Field("id")
+	assert.Equal(t, map[string]int{"int": 1}, id.GoTypes)
+	assert.Equal(t, []Coercion{CoercionBool, CoercionInt64, CoercionFloat64}, id.Coercions)
+	at := orders.Items.Fields.Field("at")
+	assert.Equal(t, []Coercion{CoercionTime}, at.Coercions)
+	assert.Equal(t, time.DateOnly, at.TimeFormat)
+}
+
+func TestInferSchema_TypedLists(t *testing.T) {
+	schema, err := InferSchema(mapsSeq(t,
+		map[string]any{
+			"tags": []string{"a", "b"},
+			"ids":  []int64{1, 2},
+			"ip":   net.IPv4(127, 0, 0, 1),
+		},
+	))
+	require.Nil(t, err)
+
+	tags := schema.Field("tags")
+	assert.Equal(t, map[string]int{"array": 1}, tags.JSONTypes)
+	require.NotNil(t, tags.Items)
+	assert.Equal(t, 2, tags.Items.Count)
+	assert.Equal(t, map[string]int{"string": 2}, tags.Items.JSONTypes)
+
+	ids := schema.Field("ids")
+	assert.Equal(t, map[string]int{"array": 1}, ids.JSONTypes)
+	require.NotNil(t, ids.Items)
+	assert.Equal(t, map[string]int{"number": 2}, ids.Items.JSONTypes)
+
+	// byte slices are encoded as strings
+	ip := schema.Field("ip")
+	assert.Equal(t, map[string]int{"string": 1}, ip.JSONTypes)
+	assert.Nil(t, ip.Items)
+}
+
+func TestInferSchema_Options(t *testing.T) {
+	schema, err := InferSchema(All("./testdata/data.csv"),
+		WithSchemaExamples(1),
+		WithSchemaCardinalityLimit(5),
+	)
+	require.Nil(t, err)
+
+	id := schema.Field("id")
+	assert.Equal(t, []any{"1"}, id.Examples)
+	assert.Equal(t, 5, id.Cardinality)
+}
+
+func TestInferSchema_Error(t *testing.T) {
+	expected := errors.New("broken")
+	_, err := InferSchema(func(yield func(*Map, error) bool) {
+		yield(nil, expected)
+	})
+	assert.ErrorIs(t, err, expected)
+}
@@ -0,0 +1,316 @@
+package dataparse
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FormatAuto can be passed to WithFormat to detect the compression and
+// format of the input from its content.
+const FormatAuto = "auto"
+
+// sniffSize is the number of bytes read from the input to detect its
+// compression and format.
+const sniffSize = 8192
+
+// SniffFunc detects a format from the start of the input.
+// If the format matches it returns true and the options to read the
+// input with, e.g. a detected separator.
+type SniffFunc func(peek []byte) ([]FromOption, bool)
+
+type formatSniffer struct {
+	name string
+	fn   SniffFunc
+}
+
+type decompressorMagic struct {
+	ext   string
+	magic []byte
+}
+
+var (
+	formatSniffers     = []formatSniffer{}
+	decompressorMagics = []decompressorMagic{}
+)
+
+// RegisterFormatSniffer registers fn to detect the format with the
+// given name when the format is detected from the content.
+//
+// Sniffers are checked in the order they are registered. Delimited
+// text is checked last as a fallback.
+//
+// RegisterFormatSniffer is not safe for concurrent use and is intended
+// to be called from init functions.
+func RegisterFormatSniffer(name string, fn SniffFunc) {
+	formatSniffers = append(formatSniffers, formatSniffer{name: name, fn: fn})
+}
+
+// RegisterDecompressorMagic registers the magic number identifying
+// input compressed with the decompressor registered for ext.
+//
+// RegisterDecompressorMagic is not safe for concurrent use and is
+// intended to be called from init functions.
+func RegisterDecompressorMagic(ext string, magic []byte) {
+	decompressorMagics = append(decompressorMagics, decompressorMagic{
+		ext:   normalizeExt(ext),
+		magic: magic,
+	})
+}
+
+// sniff detects the compression and format of cfg.reader.
+// Detected compressions are unwrapped and the name of the format and
+// the options detected for it are returned.
+func (cfg *fromConfig) sniff() (string, []FromOption, error) {
+	for {
+		buffered := bufio.NewReaderSize(cfg.reader, sniffSize)
+		cfg.reader = buffered
+
+		peek, err := buffered.Peek(sniffSize)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", nil, fmt.Errorf("dataparse: error reading input to detect format: %w", err)
+		}
+		if len(peek) == 0 {
+			return "", nil, errors.New("dataparse: cannot detect format of empty input")
+		}
+
+		ext, ok := sniffDecompressor(peek)
+		if !ok {
+			for _, sniffer := range formatSniffers {
+				if opts, ok := sniffer.fn(peek); ok {
+					return sniffer.name, opts, nil
+				}
+			}
+			if opts, ok := sniffDelimited(peek); ok {
+				return "csv", opts, nil
+			}
+			return "", nil, errors.New("dataparse: could not detect format")
+		}
+
+		decompress, ok := decompressors[ext]
+		if !ok {
+			return "", nil, fmt.Errorf("dataparse: no decompressor registered for %s", ext)
+		}
+		if err := cfg.decompress(ext, decompress); err != nil {
+			return "", nil, err
+		}
+	}
+}
+
+func sniffDecompressor(peek []byte) (string, bool) {
+	for _, dm := range decompressorMagics {
+		if bytes.HasPrefix(peek, dm.magic) {
+			return dm.ext, true
+		}
+	}
+	return "", false
+}
+
+// sniffXlsx detects Excel workbooks.
+// Workbooks are zip archives, the local file headers at the beginning
+// of the archive are expected to include xl/workbook.xml or an
+// uncompressed [Content_Types].xml declaring a spreadsheet.
+func sniffXlsx(peek []byte) ([]FromOption, bool) {
+	if !bytes.HasPrefix(peek, zipLocalHeader) {
+		return nil, false
+	}
+	for rest := peek; ; {
+		name, data, ok := zipLocalFile(rest)
+		if !ok {
+			return nil, false
+		}
+		switch name {
+		case "xl/workbook.xml":
+			return nil, true
+		case "[Content_Types].xml":
+			if bytes.Contains(data, []byte("spreadsheetml")) {
+				return nil, true
+			}
+		}
+
+		next := bytes.Index(rest[len(zipLocalHeader):], zipLocalHeader)
+		if next < 0 {
+			return nil, false
+		}
+		rest = rest[len(zipLocalHeader)+next:]
+	}
+}
+
+var zipLocalHeader = []byte("PK\x03\x04")
+
+// zipLocalFile returns the name of the file in the zip local file
+// header at the beginning of b and its data if it is stored
+// uncompressed.
+func zipLocalFile(b []byte) (string, []byte, bool) {
+	const headerSize = 30
+	if len(b) < headerSize {
+		return "", nil, false
+	}
+	method := binary.LittleEndian.Uint16(b[8:])
+	nameLen := int(binary.LittleEndian.Uint16(b[26:]))
+	extraLen := int(binary.LittleEndian.Uint16(b[28:]))
+	if len(b) < headerSize+nameLen {
+		return "", nil, false
+	}
+	name := string(b[headerSize : headerSize+nameLen])
+
+	var data []byte
+	if method == zip.Store && len(b) > headerSize+nameLen+extraLen {
+		data = b[headerSize+nameLen+extraLen:]
+	}
+	return name, data, true
+}
+
+// sniffZip detects zip archives that are not workbooks.
+func sniffZip(peek []byte) ([]FromOption, bool) {
+	return nil, bytes.HasPrefix(peek, zipLocalHeader)
+}
+
+// sniffTar checks for the magic of ustar and GNU tar headers.
+func sniffTar(peek []byte) ([]FromOption, bool) {
+	const magicOffset = 257
+	if len(peek) < magicOffset+5 {
+		return nil, false
+	}
+	return nil, bytes.Equal(peek[magicOffset:magicOffset+5], []byte("ustar"))
+}
+
+// sniffParquet checks for the magic at the beginning of Parquet files.
+func sniffParquet(peek []byte) ([]FromOption, bool) {
+	return nil, bytes.HasPrefix(peek, []byte("PAR1"))
+}
+
+// sniffAvro checks for the magic at the beginning of Avro object
+// container files.
+func sniffAvro(peek []byte) ([]FromOption, bool) {
+	return nil, bytes.HasPrefix(peek, []byte("Obj\x01"))
+}
+
+// sniffJson detects JSON documents, streams of JSON documents and
+// arrays.
+func sniffJson(peek []byte) ([]FromOption, bool) {
+	peek = bytes.TrimPrefix(peek, []byte("\xef\xbb\xbf"))
+	peek = bytes.TrimLeft(peek, " \t\r\n")
+	return nil, len(peek) > 0 && (peek[0] == '{' || peek[0] == '[')
+}
+
+// sniffYaml detects YAML streams starting with a directive or
+// a document separator.
+// Documents without either are indistinguishable from plain text.
+func sniffYaml(peek []byte) ([]FromOption, bool) {
+	peek = bytes.TrimLeft(peek, " \t\r\n")
+	return nil, bytes.HasPrefix(peek, []byte("%YAML")) || bytes.HasPrefix(peek, []byte("---"))
+}
+
+// sniffXml detects XML documents starting with a declaration or an
+// element.
+func sniffXml(peek []byte) ([]FromOption, bool) {
+	peek = bytes.TrimPrefix(peek, []byte("\xef\xbb\xbf"))
+	peek = bytes.TrimLeft(peek, " \t\r\n")
+	if bytes.HasPrefix(peek, []byte("<?xml")) {
+		return nil, true
+	}
+	return nil, len(peek) > 1 && peek[0] == '<' && isLetter(peek[1])
+}
+
+// sniffHtml detects HTML documents and fragments starting with
+// a table.
+func sniffHtml(peek []byte) ([]FromOption, bool) {
+	peek = bytes.TrimPrefix(peek, []byte("\xef\xbb\xbf"))
+	peek = bytes.ToLower(bytes.TrimLeft(peek, " \t\r\n"))
+	for _, prefix := range []string{"<!doctype html", "<html", "<table"} {
+		if bytes.HasPrefix(peek, []byte(prefix)) {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// sniffMarkdown detects Markdown documents containing a pipe table by
+// the row separating the header from the body.
+func sniffMarkdown(peek []byte) ([]FromOption, bool) {
+	for line := range bytes.Lines(peek) {
+		if bytes.Contains(line, []byte("|")) && markdownDelimiterRow.Match(line) {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// sniffDelimitedLines is the maximum number of lines checked to detect
+// delimited text.
+const sniffDelimitedLines = 20
+
+// sniffDelimited detects delimited text like CSV and returns the
+// detected separator as option.
+// A separator is detected if it occurs the same number of times outside
+// of quotes in each line.
+func sniffDelimited(peek []byte) ([]FromOption, bool) {
+	if bytes.IndexByte(peek, 0) >= 0 {
+		return nil, false
+	}
+
+	// only check complete lines if the input is longer than peek
+	if i := bytes.LastIndexByte(peek, '\n'); i > 0 {
+		peek = peek[:i]
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(string(peek), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == sniffDelimitedLines {
+			break
+		}
+	}
+
+	var best rune
+	bestCount := 0
+	for _, sep := range []rune{',', '\t', ';', '|'} {
+		count := -1
+		for _, line := range lines {
+			c := countOutsideQuotes(line, sep)
+			if count == -1 {
+				count = c
+			} else if c != count {
+				count = 0
+				break
+			}
+		}
+		if count > bestCount {
+			best = sep
+			bestCount = count
+		}
+	}
+
+	if bestCount == 0 {
+		return nil, false
+	}
+	return []FromOption{WithSeparator(string(best))}, true
+}
+
+func countOutsideQuotes(line string, sep rune) int {
+	count := 0
+	quoted := false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			count++
+		}
+	}
+	return count
+}
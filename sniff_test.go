@@ -0,0 +1,156 @@
+package dataparse
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderAll_Sniff(t *testing.T) {
+	csvData, err := os.ReadFile("./testdata/data.csv")
+	require.Nil(t, err)
+	jsonData, err := os.ReadFile("./testdata/data.json")
+	require.Nil(t, err)
+	xlsxData, err := os.ReadFile("./testdata/data.xlsx")
+	require.Nil(t, err)
+	yamlData, err := os.ReadFile("./testdata/data.yaml")
+	require.Nil(t, err)
+
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	_, err = w.Write(jsonData)
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+
+	tsvData := strings.ReplaceAll(string(csvData), ",", "\t")
+	semicolonData := strings.ReplaceAll(string(csvData), ",", ";")
+
+	for name, input := range map[string][]byte{
+		"csv":       csvData,
+		"tsv":       []byte(tsvData),
+		"semicolon": []byte(semicolonData),
+		"json":      jsonData,
+		"json.gz":   gzipped.Bytes(),
+		"xlsx":      xlsxData,
+		"yaml":      yamlData,
+	} {
+		t.Run(name, func(t *testing.T) {
+			count := 0
+			for m, err := range ReaderAll(bytes.NewReader(input)) {
+				require.Nil(t, err)
+				require.True(t, m.Has("first_name"))
+				count++
+			}
+			assert.Equal(t, 10, count)
+		})
+	}
+}
+
+func TestFromReader_Undetectable(t *testing.T) {
+	_, err := FromReader(bytes.NewReader([]byte{0x00, 0x01}))
+	require.ErrorContains(t, err, "could not detect format")
+
+	_, err = FromReader(bytes.NewReader(nil))
+	require.ErrorContains(t, err, "empty input")
+}
+
+func TestReaderAll_SniffZipWithXlPath(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	f, err := w.Create("tools/xl/readme.csv")
+	require.Nil(t, err)
+	_, err = f.Write([]byte("id\n1\n2\n"))
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+
+	ids, errs := collect(t, ReaderAll(bytes.NewReader(buf.Bytes())))
+	assert.Empty(t, errs)
+	assert.Equal(t, []int{1, 2}, ids)
+}
+
+func TestSniffXlsx(t *testing.T) {
+	xlsxData, err := os.ReadFile("./testdata/data.xlsx")
+	require.Nil(t, err)
+	_, ok := sniffXlsx(xlsxData)
+	assert.True(t, ok)
+
+	archive := func(name, data string) []byte {
+		buf := &bytes.Buffer{}
+		w := zip.NewWriter(buf)
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		require.Nil(t, err)
+		_, err = f.Write([]byte(data))
+		require.Nil(t, err)
+		require.Nil(t, w.Close())
+		return buf.Bytes()
+	}
+
+	_, ok = sniffXlsx(archive("[Content_Types].xml", `<Override ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`))
+	assert.True(t, ok)
+	_, ok = sniffXlsx(archive("[Content_Types].xml", `<Types/>`))
+	assert.False(t, ok)
+	_, ok = sniffXlsx(archive("tools/xl/readme.csv", "id\n1\n"))
+	assert.False(t, ok)
+}
+
+func TestFrom_SniffUnknownExtension(t *testing.T) {
+	csvData, err := os.ReadFile("./testdata/data.csv")
+	require.Nil(t, err)
+
+	dir := t.TempDir()
+	for _, name := range []string{"export", "data.txt", "report.dat"} {
+		path := filepath.Join(dir, name)
+		require.Nil(t, os.WriteFile(path, csvData, 0o600))
+
+		m, err := FromSingle(path)
+		require.Nil(t, err)
+		assert.Equal(t, "Robbie", m.MustGet("first_name").MustString())
+	}
+}
+
+func TestFrom_WithFormat(t *testing.T) {
+	csvData, err := os.ReadFile("./testdata/data.csv")
+	require.Nil(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mislabeled.json")
+	require.Nil(t, os.WriteFile(path, csvData, 0o600))
+
+	_, err = FromSingle(path)
+	require.NotNil(t, err)
+
+	m, err := FromSingle(path, WithFormat("csv"))
+	require.Nil(t, err)
+	assert.Equal(t, "Robbie", m.MustGet("first_name").MustString())
+
+	m, err = FromSingle(path, WithFormat(FormatAuto))
+	require.Nil(t, err)
+	assert.Equal(t, "Robbie", m.MustGet("first_name").MustString())
+
+	_, err = FromSingle(path, WithFormat("unknown"))
+	require.ErrorContains(t, err, `unknown format: "unknown"`)
+}
+
+func TestSniffDelimited(t *testing.T) {
+	opts, ok := sniffDelimited([]byte("a|b|c\n1|\"x|y\"|3\n4|5|6"))
+	require.True(t, ok)
+	assert.Equal(t, "|", newFromConfig(opts...).separator)
+
+	// the incomplete last line is ignored
+	opts, ok = sniffDelimited([]byte("a;b\n1;2\n3;4;5"))
+	require.True(t, ok)
+	assert.Equal(t, ";", newFromConfig(opts...).separator)
+
+	_, ok = sniffDelimited([]byte("just some text\nwithout separators\n"))
+	assert.False(t, ok)
+
+	_, ok = sniffDelimited([]byte("a,b\n1,2,3\n4\n"))
+	assert.False(t, ok)
+}
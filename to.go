@@ -0,0 +1,194 @@
+package dataparse
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WriterFunc writes the maps of seq to the writer.
+// It returns the first error yielded by seq or encountered while
+// writing.
+type WriterFunc func(io.Writer, iter.Seq2[*Map, error], ...FromOption) error
+
+// CompressorFunc returns a writer compressing into the passed writer.
+// The returned writer is closed before the underlying writer.
+type CompressorFunc func(io.Writer) (io.WriteCloser, error)
+
+type writerFormat struct {
+	name       string
+	extensions []string
+	fn         WriterFunc
+}
+
+var (
+	writers      = map[string]writerFormat{}
+	writersByExt = map[string]string{}
+	compressors  = map[string]CompressorFunc{}
+)
+
+func init() {
+	RegisterWriter("json", []string{".json"}, ToJson)
+	RegisterWriter("ndjson", []string{".ndjson", ".jsonl"}, ToNdjson)
+	RegisterWriter("csv", []string{".csv"}, ToCsv)
+	RegisterWriter("tsv", []string{".tsv"}, func(w io.Writer, seq iter.Seq2[*Map, error], opts ...FromOption) error {
+		// Default to tab as separator for .tsv
+		return ToCsv(w, seq, append([]FromOption{WithSeparator("\t")}, opts...)...)
+	})
+
+	RegisterCompressor(".gz", func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+}
+
+// RegisterWriter registers a writer for a format under the given name.
+// To uses the writer for files with one of the extensions.
+//
+// Registering a name or extension again replaces the previous
+// registration, which allows to replace builtin writers.
+//
+// RegisterWriter is not safe for concurrent use and is intended to be
+// called from init functions.
+func RegisterWriter(name string, extensions []string, fn WriterFunc) {
+	if previous, ok := writers[name]; ok {
+		for _, ext := range previous.extensions {
+			if writersByExt[ext] == name {
+				delete(writersByExt, ext)
+			}
+		}
+	}
+
+	normalized := make([]string, len(extensions))
+	for i, ext := range extensions {
+		normalized[i] = normalizeExt(ext)
+		writersByExt[normalized[i]] = name
+	}
+
+	writers[name] = writerFormat{
+		name:       name,
+		extensions: normalized,
+		fn:         fn,
+	}
+}
+
+// RegisterCompressor registers a compressor for the given extension.
+// To compresses files with the extension using the compressor and
+// selects the format based on the remaining extension, e.g. data.csv.gz
+// is written as gzip compressed CSV.
+//
+// RegisterCompressor is not safe for concurrent use and is intended to
+// be called from init functions.
+func RegisterCompressor(ext string, fn CompressorFunc) {
+	compressors[normalizeExt(ext)] = fn
+}
+
+// Writers returns the names of the registered writers.
+func Writers() []string {
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// To writes the maps of seq to a file.
+//
+// The format is selected by the file extension or passed with
+// WithFormat, see RegisterWriter for the names of the formats.
+// Builtin are:
+//   - .json: a JSON array, see ToJson
+//   - .ndjson and .jsonl: newline delimited JSON, see ToNdjson
+//   - .csv and .tsv: delimited text, see ToCsv
+//
+// Files ending in .gz are gzip compressed, e.g. data.csv.gz.
+//
+// Reading from seq stops at the first error, which is returned. The
+// maps written until then are kept in the file, JSON arrays are closed
+// so the file stays valid, see ToJson.
+func To(path string, seq iter.Seq2[*Map, error], opts ...FromOption) error {
+	cfg := newFromConfig(opts...)
+
+	name := path
+	compress := []CompressorFunc{}
+	for {
+		fn, ok := compressors[normalizeExt(filepath.Ext(name))]
+		if !ok {
+			break
+		}
+		compress = append(compress, fn)
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	formatName := cfg.format
+	if formatName == "" {
+		formatName = writersByExt[normalizeExt(filepath.Ext(name))]
+		if formatName == "" {
+			return fmt.Errorf("dataparse: unhandled file extension: %q", filepath.Ext(name))
+		}
+	}
+	f, ok := writers[formatName]
+	if !ok {
+		return fmt.Errorf("dataparse: unknown format: %q", formatName)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("dataparse: error creating file: %w", err)
+	}
+
+	var w io.Writer = file
+	closers := []func() error{file.Close}
+	retErr := func() error {
+		for _, fn := range compress {
+			compressed, err := fn(w)
+			if err != nil {
+				return fmt.Errorf("dataparse: error creating compressor: %w", err)
+			}
+			w = compressed
+			closers = append(closers, compressed.Close)
+		}
+		return f.fn(w, seq, opts...)
+	}()
+
+	slices.Reverse(closers)
+	for _, closer := range closers {
+		if err := closer(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("dataparse: error closing file: %w", err))
+		}
+	}
+	return retErr
+}
+
+// ToWriter writes the maps of seq to w in the format with the given
+// name, see To.
+func ToWriter(w io.Writer, format string, seq iter.Seq2[*Map, error], opts ...FromOption) error {
+	f, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("dataparse: unknown format: %q", format)
+	}
+	return f.fn(w, seq, opts...)
+}
+
+// each calls fn for each map of seq and stops at the first error of
+// seq or fn, or when the context is cancelled.
+func (cfg *fromConfig) each(seq iter.Seq2[*Map, error], fn func(*Map) error) error {
+	for m, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := cfg.ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package dataparse
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// ToCsv writes the maps of seq to w as CSV, separated by the separator
+// passed with WithSeparator.
+//
+// Nested maps are flattened into columns with the keys joined by dots,
+// e.g. {"a": {"b": 1}} is written to the column "a.b", which Map.Get
+// resolves when the file is read again.
+//
+// The columns are the headers passed with WithHeaders or the sorted
+// keys of the first map. Keys of later maps that are not in the
+// headers are skipped and missing keys are written as empty values.
+//
+// Values are written as strings, see Value.String. Times are written
+// as RFC 3339, []byte as base64 and lists as JSON.
+func ToCsv(w io.Writer, seq iter.Seq2[*Map, error], opts ...FromOption) error {
+	cfg := newFromConfig(opts...)
+	if len(cfg.separator) != 1 {
+		return fmt.Errorf("dataparse: separator must be a string of length one for csv, got %q", cfg.separator)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = rune(cfg.separator[0])
+
+	headers := cfg.headers
+	if len(headers) > 0 {
+		if err := writer.Write(headers); err != nil {
+			return err
+		}
+	}
+
+	err := cfg.each(seq, func(m *Map) error {
+		flat := map[string]any{}
+		flattenMap(flat, "", m.Data)
+
+		if len(headers) == 0 {
+			for key := range flat {
+				headers = append(headers, key)
+			}
+			slices.Sort(headers)
+			if err := writer.Write(headers); err != nil {
+				return err
+			}
+		}
+
+		record := make([]string, len(headers))
+		for i, header := range headers {
+			value, err := csvValue(flat[header])
+			if err != nil {
+				return NewErrRecord(m.Provenance, fmt.Errorf("dataparse: error encoding column %q: %w", header, err))
+			}
+			record[i] = value
+		}
+		return writer.Write(record)
+	})
+	writer.Flush()
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// flattenMap sets the values of m in flat with the keys of nested maps
+// joined by dots.
+func flattenMap[K comparable](flat map[string]any, prefix string, m map[K]any) {
+	for key, value := range m {
+		flatKey := prefix + fmt.Sprint(key)
+		switch typed := value.(type) {
+		case *Map:
+			flattenMap(flat, flatKey+".", typed.Data)
+		case map[any]any:
+			flattenMap(flat, flatKey+".", typed)
+		case map[string]any:
+			flattenMap(flat, flatKey+".", typed)
+		default:
+			flat[flatKey] = value
+		}
+	}
+}
+
+// csvValue returns the string written to CSV for v.
+func csvValue(v any) (string, error) {
+	switch typed := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typed, nil
+	case time.Time:
+		return typed.Format(time.RFC3339Nano), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(typed), nil
+	}
+
+	// lists like []any or []string are written as JSON, named byte
+	// slices like net.IP are formatted by Value.String
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		b, err := json.Marshal(jsonValue(v))
+		return string(b), err
+	}
+	return NewValue(v).String()
+}
@@ -0,0 +1,60 @@
+package dataparse
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToCsv(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.Nil(t, ToCsv(buf, mapsSeq(t,
+		map[string]any{
+			"id":      1,
+			"name":    "a, b",
+			"address": map[string]any{"city": "Berlin", "geo": map[any]any{"lat": 52.5}},
+			"tags":    []any{"x", "y"},
+			"created": time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		map[string]any{"id": 2, "extra": "skipped"},
+	)))
+	assert.Equal(t, "address.city,address.geo.lat,created,id,name,tags\n"+
+		"Berlin,52.5,2026-01-02T03:04:05Z,1,\"a, b\",\"[\"\"x\"\",\"\"y\"\"]\"\n"+
+		",,,2,,\n", buf.String())
+
+	// flattened keys resolve with Map.Get after reading
+	m, err := single(CsvAll(bytes.NewReader(buf.Bytes())))
+	require.Nil(t, err)
+	assert.Equal(t, "Berlin", m.MustGet("address.city").MustString())
+}
+
+func TestToCsv_Lists(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.Nil(t, ToCsv(buf, mapsSeq(t,
+		map[string]any{
+			"strings": []string{"a", "b"},
+			"ints":    []int{1, 2},
+			"maps":    []map[any]any{{"a": 1}},
+			"bytes":   []byte("ab"),
+			"ip":      net.ParseIP("192.168.1.1"),
+		},
+	)))
+	assert.Equal(t, "bytes,ints,ip,maps,strings\n"+
+		"YWI=,\"[1,2]\",192.168.1.1,\"[{\"\"a\"\":1}]\",\"[\"\"a\"\",\"\"b\"\"]\"\n", buf.String())
+}
+
+func TestToCsv_WithHeaders(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.Nil(t, ToCsv(buf, mapsSeq(t,
+		map[string]any{"a": 1, "b": map[string]any{"c": 2}, "d": 3},
+	), WithHeaders("d", "b.c", "missing"), WithSeparator(";")))
+	assert.Equal(t, "d;b.c;missing\n3;2;\n", buf.String())
+}
+
+func TestToCsv_Separator(t *testing.T) {
+	require.ErrorContains(t, ToCsv(&bytes.Buffer{}, mapsSeq(t), WithSeparator("::")), "separator must be")
+}
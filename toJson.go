@@ -0,0 +1,122 @@
+package dataparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"math/big"
+	"reflect"
+)
+
+// ToJson writes the maps of seq to w as a JSON array with one map per
+// line.
+//
+// Keys are converted to strings with fmt.Sprint and decimals like
+// *big.Rat are written as numbers with all their digits, fractions
+// without a finite decimal representation like 1/3 are rounded to 32
+// digits. All other values are encoded with encoding/json, e.g.
+// time.Time as RFC 3339 and []byte as base64.
+//
+// If seq or encoding a map fails the array is closed after the maps
+// written until then before the error is returned, so the output is
+// valid JSON.
+func ToJson(w io.Writer, seq iter.Seq2[*Map, error], opts ...FromOption) error {
+	cfg := newFromConfig(opts...)
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	count := 0
+	var writeErr error
+	err := cfg.each(seq, func(m *Map) error {
+		buf.Reset()
+		if count == 0 {
+			buf.WriteString("[\n  ")
+		} else {
+			buf.WriteString(",\n  ")
+		}
+		if err := enc.Encode(jsonValue(m.Data)); err != nil {
+			return NewErrRecord(m.Provenance, fmt.Errorf("dataparse: error encoding json: %w", err))
+		}
+		// the encoder terminates each value with a newline
+		buf.Truncate(buf.Len() - 1)
+		count++
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			writeErr = err
+			return err
+		}
+		return nil
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+
+	// the array is closed even if seq failed so the output stays valid
+	end := "\n]\n"
+	if count == 0 {
+		end = "[]\n"
+	}
+	_, endErr := io.WriteString(w, end)
+	if err != nil {
+		return err
+	}
+	return endErr
+}
+
+// ToNdjson writes the maps of seq to w as newline delimited JSON with
+// one map per line, see ToJson for the encoding of values.
+func ToNdjson(w io.Writer, seq iter.Seq2[*Map, error], opts ...FromOption) error {
+	cfg := newFromConfig(opts...)
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return cfg.each(seq, func(m *Map) error {
+		if err := enc.Encode(jsonValue(m.Data)); err != nil {
+			return NewErrRecord(m.Provenance, fmt.Errorf("dataparse: error encoding json: %w", err))
+		}
+		return nil
+	})
+}
+
+// jsonValue returns v with maps keyed by strings so it can be encoded
+// by encoding/json, including the maps in lists like []map[any]any.
+func jsonValue(v any) any {
+	switch typed := v.(type) {
+	case *Map:
+		return jsonValue(typed.Data)
+	case map[any]any:
+		m := make(map[string]any, len(typed))
+		for key, value := range typed {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case map[string]any:
+		m := make(map[string]any, len(typed))
+		for key, value := range typed {
+			m[key] = jsonValue(value)
+		}
+		return m
+	case []any:
+		list := make([]any, len(typed))
+		for i, value := range typed {
+			list[i] = jsonValue(value)
+		}
+		return list
+	case *big.Rat:
+		return json.Number(ratString(typed))
+	}
+
+	// []byte is encoded as base64
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 && !rv.IsNil() {
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = jsonValue(rv.Index(i).Interface())
+		}
+		return list
+	}
+	return v
+}
@@ -0,0 +1,63 @@
+package dataparse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJson(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.Nil(t, ToJson(buf, mapsSeq(t,
+		map[string]any{"a": 1, "html": "<b>"},
+		map[string]any{"nested": map[any]any{1: "x"}, "price": big.NewRat(25, 2)},
+	)))
+	assert.Equal(t, "[\n"+
+		"  {\"a\":1,\"html\":\"<b>\"},\n"+
+		"  {\"nested\":{\"1\":\"x\"},\"price\":12.5}\n"+
+		"]\n", buf.String())
+}
+
+func TestToJson_Empty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.Nil(t, ToJson(buf, mapsSeq(t)))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestToJson_SeqError(t *testing.T) {
+	errTest := errors.New("test error")
+	seq := func(yield func(*Map, error) bool) {
+		m, _ := NewMap(map[string]any{"a": 1})
+		if yield(m, nil) {
+			yield(nil, errTest)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	require.ErrorIs(t, ToJson(buf, seq), errTest)
+	assert.Equal(t, "[\n  {\"a\":1}\n]\n", buf.String())
+	assert.True(t, json.Valid(buf.Bytes()))
+
+	buf.Reset()
+	require.ErrorIs(t, ToJson(buf, func(yield func(*Map, error) bool) { yield(nil, errTest) }), errTest)
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestToNdjson(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	buf := &bytes.Buffer{}
+	require.Nil(t, ToNdjson(buf, mapsSeq(t,
+		map[string]any{"ts": ts, "raw": []byte{0x01}},
+		map[string]any{"list": []any{map[any]any{"a": nil}}},
+		map[string]any{"maps": []map[any]any{{1: "x"}}, "third": big.NewRat(1, 3)},
+	)))
+	assert.Equal(t, "{\"raw\":\"AQ==\",\"ts\":\"2026-01-02T03:04:05Z\"}\n"+
+		"{\"list\":[{\"a\":null}]}\n"+
+		"{\"maps\":[{\"1\":\"x\"}],\"third\":0.33333333333333333333333333333333}\n", buf.String())
+}
@@ -0,0 +1,117 @@
+package dataparse
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapsSeq returns an iterator over maps created from data.
+func mapsSeq(t *testing.T, data ...map[string]any) iter.Seq2[*Map, error] {
+	t.Helper()
+	maps := make([]*Map, len(data))
+	for i, d := range data {
+		m, err := NewMap(d)
+		require.Nil(t, err)
+		maps[i] = m
+	}
+	return func(yield func(*Map, error) bool) {
+		for _, m := range maps {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestTo_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	expected := []map[any]any{}
+	for m, err := range All("./testdata/data.csv") {
+		require.Nil(t, err)
+		expected = append(expected, m.Data)
+	}
+	require.NotEmpty(t, expected)
+
+	for _, name := range []string{"out.json", "out.ndjson", "out.jsonl", "out.csv", "out.tsv", "out.csv.gz", "out.ndjson.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.Nil(t, To(path, All("./testdata/data.csv")))
+
+			actual := []map[any]any{}
+			for m, err := range All(path) {
+				require.Nil(t, err)
+				actual = append(actual, m.Data)
+			}
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestTo_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.ndjson.gz")
+	require.Nil(t, To(path, mapsSeq(t, map[string]any{"a": "1"})))
+
+	f, err := os.Open(path)
+	require.Nil(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.Nil(t, err)
+	b, err := io.ReadAll(gz)
+	require.Nil(t, err)
+	assert.Equal(t, "{\"a\":\"1\"}\n", string(b))
+}
+
+func TestTo_WithFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	require.Nil(t, To(path, mapsSeq(t, map[string]any{"a": "1"}), WithFormat("csv")))
+
+	b, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, "a\n1\n", string(b))
+}
+
+func TestTo_UnhandledExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	require.ErrorContains(t, To(path, mapsSeq(t)), "unhandled file extension")
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTo_SeqError(t *testing.T) {
+	errTest := errors.New("test error")
+	seq := func(yield func(*Map, error) bool) {
+		m, _ := NewMap(map[string]any{"a": 1})
+		if yield(m, nil) {
+			yield(nil, errTest)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "out.ndjson")
+	require.ErrorIs(t, To(path, seq), errTest)
+
+	// maps written before the error are kept
+	b, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, "{\"a\":1}\n", string(b))
+
+	// json arrays are closed
+	path = filepath.Join(t.TempDir(), "out.json")
+	require.ErrorIs(t, To(path, seq), errTest)
+	b, err = os.ReadFile(path)
+	require.Nil(t, err)
+	assert.True(t, json.Valid(b))
+}
+
+func TestToWriter_UnknownFormat(t *testing.T) {
+	require.ErrorContains(t, ToWriter(io.Discard, "unknown", mapsSeq(t)), "unknown format")
+}
@@ -51,6 +51,8 @@ func AddCustomToFunc(fn CustomToFunc) {
 //
 // If the parameter satisfies the Fromer interface it will be used to
 // set the value.
+//
+// Values stored in empty interfaces are set as is.
 func (v Value) To(other any, opts ...ToOption) error {
 	if fromer, ok := other.(Fromer); ok {
 		return fromer.From(v)
@@ -78,6 +80,16 @@ func (v Value) To(other any, opts ...ToOption) error {
 		target = target.Elem()
 	}
 
+	// store the data as is in empty interfaces
+	if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+		if v.Data == nil {
+			target.SetZero()
+		} else {
+			target.Set(reflect.ValueOf(v.Data))
+		}
+		return nil
+	}
+
 	// handle slices but skip named types (like net.IP which is
 	// a []byte)
 	if target.Type().Name() == "" && target.Kind() == reflect.Slice || target.Kind() == reflect.Array {
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -60,6 +61,12 @@ func (v Value) Int() (int, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsInt64() {
+			return int(typed.Num().Int64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return int(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Varint(typed)
 		if numBytes <= 0 {
@@ -136,6 +143,12 @@ func (v Value) Int8() (int8, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsInt64() {
+			return int8(typed.Num().Int64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return int8(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Varint(typed)
 		if numBytes <= 0 {
@@ -212,6 +225,12 @@ func (v Value) Int16() (int16, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsInt64() {
+			return int16(typed.Num().Int64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return int16(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Varint(typed)
 		if numBytes <= 0 {
@@ -288,6 +307,12 @@ func (v Value) Int32() (int32, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsInt64() {
+			return int32(typed.Num().Int64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return int32(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Varint(typed)
 		if numBytes <= 0 {
@@ -364,6 +389,12 @@ func (v Value) Int64() (int64, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsInt64() {
+			return int64(typed.Num().Int64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return int64(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Varint(typed)
 		if numBytes <= 0 {
@@ -440,6 +471,12 @@ func (v Value) Uint() (uint, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsUint64() {
+			return uint(typed.Num().Uint64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return uint(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Uvarint(typed)
 		if numBytes <= 0 {
@@ -516,6 +553,12 @@ func (v Value) Uint8() (uint8, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsUint64() {
+			return uint8(typed.Num().Uint64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return uint8(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Uvarint(typed)
 		if numBytes <= 0 {
@@ -592,6 +635,12 @@ func (v Value) Uint16() (uint16, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsUint64() {
+			return uint16(typed.Num().Uint64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return uint16(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Uvarint(typed)
 		if numBytes <= 0 {
@@ -668,6 +717,12 @@ func (v Value) Uint32() (uint32, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsUint64() {
+			return uint32(typed.Num().Uint64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return uint32(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Uvarint(typed)
 		if numBytes <= 0 {
@@ -744,6 +799,12 @@ func (v Value) Uint64() (uint64, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		if typed.IsInt() && typed.Num().IsUint64() {
+			return uint64(typed.Num().Uint64()), nil
+		}
+		parsed, _ := typed.Float64()
+		return uint64(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Uvarint(typed)
 		if numBytes <= 0 {
@@ -813,6 +874,9 @@ func (v Value) Float32() (float32, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		parsed, _ := typed.Float64()
+		return float32(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Uvarint(typed)
 		if numBytes <= 0 {
@@ -882,6 +946,9 @@ func (v Value) Float64() (float64, error) {
 			return 1, nil
 		}
 		return 0, nil
+	case *big.Rat:
+		parsed, _ := typed.Float64()
+		return float64(parsed), nil
 	case []byte:
 		ret, numBytes := binary.Uvarint(typed)
 		if numBytes <= 0 {
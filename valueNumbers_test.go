@@ -2,6 +2,7 @@ package dataparse
 
 import (
 	"math"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,6 +58,14 @@ func TestValue_Int(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, 12345678, parsed)
 
+	parsed, err = NewValue(big.NewRat(1250, 10)).Int()
+	require.Nil(t, err)
+	assert.Equal(t, 125, parsed)
+
+	parsed, err = NewValue(big.NewRat(1255, 10)).Int()
+	require.Nil(t, err)
+	assert.Equal(t, 125, parsed)
+
 	parsed, err = NewValue(true).Int()
 	require.Nil(t, err)
 	assert.Equal(t, 1, parsed)
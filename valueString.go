@@ -2,6 +2,7 @@ package dataparse
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 )
@@ -19,11 +20,45 @@ func (v Value) String() (string, error) {
 	switch typed := v.Data.(type) {
 	case rune:
 		return string(typed), nil
+	case *big.Rat:
+		return ratString(typed), nil
 	default:
 		return fmt.Sprintf("%v", v.Data), nil
 	}
 }
 
+// ratString formats r as a decimal number with as many digits as
+// needed to represent it exactly, e.g. decimals read from Parquet or
+// Avro.
+// Fractions without a finite decimal representation are rounded to 32
+// digits.
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	denom := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, factor := range []int64{2, 5} {
+		n := 0
+		f := big.NewInt(factor)
+		mod := new(big.Int)
+		for {
+			q, m := new(big.Int).QuoRem(denom, f, mod)
+			if m.Sign() != 0 {
+				break
+			}
+			denom = q
+			n++
+		}
+		digits = max(digits, n)
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		digits = 32
+	}
+	return r.FloatString(digits)
+}
+
 // MustString is the error-ignoring version of String.
 func (v Value) MustString() string {
 	s, _ := v.String()
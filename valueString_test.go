@@ -1,6 +1,7 @@
 package dataparse
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,11 @@ func TestValue_String(t *testing.T) {
 	// float to string
 	assert.Equal(t, "1", NewValue(1.0).MustString())
 
+	// decimal to string
+	assert.Equal(t, "12.5", NewValue(big.NewRat(1250, 100)).MustString())
+	assert.Equal(t, "-0.001", NewValue(big.NewRat(-1, 1000)).MustString())
+	assert.Equal(t, "42", NewValue(big.NewRat(42, 1)).MustString())
+
 	assert.Equal(t, "1.4", NewValue(1.4).MustString())
 
 	// rune to string
@@ -24,6 +24,8 @@ func (v Value) Time() (time.Time, error) {
 		return time.Unix(int64(reflect.ValueOf(v.Data).Float()), 0), nil
 	case string:
 		return ParseTime(typed)
+	case time.Time:
+		return typed, nil
 	default:
 		return time.Time{}, nil
 	}
@@ -3,6 +3,7 @@ package dataparse
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/stretchr/testify/require"
@@ -38,3 +39,10 @@ func TestValue_Time(t *testing.T) {
 	_, err = NewValue(u64).Time()
 	require.NotNil(t, err)
 }
+
+func TestValue_Time_Time(t *testing.T) {
+	now := time.Now()
+	v, err := NewValue(now).Time()
+	require.Nil(t, err)
+	require.Equal(t, now, v)
+}
@@ -162,6 +162,15 @@ func TestValue_To_PtrSlice(t *testing.T) {
 	assert.Equal(t, "a single string", target2[0])
 }
 
+func TestValue_To_Any(t *testing.T) {
+	var a any
+	require.Nil(t, NewValue(map[string]any{"a": 1}).To(&a))
+	assert.Equal(t, map[string]any{"a": 1}, a)
+
+	require.Nil(t, NewValue(nil).To(&a))
+	assert.Nil(t, a)
+}
+
 func TestValue_List(t *testing.T) {
 	cfg := newFromConfig()
 